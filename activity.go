@@ -0,0 +1,39 @@
+package status
+
+import (
+	"slices"
+
+	"github.com/gonebot-dev/gonebot/message"
+	"github.com/gonebot-dev/goneplugin-status/i18n"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+)
+
+// Count messages by group and user, and the plugins wrapped with Track, for "status activity".
+// Redaction.IDs masks the users and groups, as does redacting any other field
+var EnableActivity = false
+
+// Users and groups never counted, users can also opt out with "status activity optout"
+var ActivityOptOutUsers = []string{}
+var ActivityOptOutGroups = []string{}
+
+// activityHandler counts every message and lets the other handlers see it
+func activityHandler(incomingMsg message.Message, resultMsg *message.Message) bool {
+	if slices.Contains(ActivityOptOutGroups, incomingMsg.GroupID) && incomingMsg.IsGroup {
+		return false
+	}
+	if slices.Contains(ActivityOptOutUsers, incomingMsg.SenderID) {
+		incomingMsg.SenderID = ""
+	}
+	sysinfo.RecordActivity(incomingMsg)
+	return false
+}
+
+// activityCommand opts the sender out of the activity board or back in, it returns false for other arguments
+func activityCommand(msg message.Message, args []string, resultMsg *message.Message) bool {
+	if len(args) != 1 || (args[0] != "optout" && args[0] != "optin") {
+		return false
+	}
+	sysinfo.OptOut(msg.SenderID, args[0] == "optout")
+	resultMsg.AddTextSegment(i18n.T(localeOf(msg), "leaderboard."+args[0]))
+	return true
+}
@@ -0,0 +1,347 @@
+package alert
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/history"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+	"github.com/gonebot-dev/goneplugin-status/threshold"
+)
+
+// Metrics besides threshold.CPU, threshold.Memory and threshold.Disk
+const (
+	Load1  = "load1"
+	Load5  = "load5"
+	Load15 = "load15"
+)
+
+// Comparisons
+const (
+	Above        = ">"
+	AboveOrEqual = ">="
+	Below        = "<"
+	BelowOrEqual = "<="
+)
+
+// Severities
+const (
+	Info     = "info"
+	Warning  = "warning"
+	Critical = "critical"
+)
+
+// States of an alert
+const (
+	// Pending alerts meet their rule, but not for long enough yet
+	Pending = "pending"
+	Firing  = "firing"
+	// Resolved alerts are only seen in notifications
+	Resolved = "resolved"
+)
+
+// Rule fires an alert when a metric compares to a threshold for some time
+type Rule struct {
+	// Name of the rule, unique
+	Name string `json:"name"`
+	// Metric checked, threshold.CPU, threshold.Memory, threshold.Disk or a load average
+	Metric string `json:"metric"`
+	// Mountpoint checked by disk rules, every disk is checked on its own when empty
+	Mount string `json:"mount,omitempty"`
+	// Comparison of the metric to the threshold, Above when empty
+	Comparison string `json:"comparison,omitempty"`
+	// Threshold, in percent for every metric but the load averages
+	Threshold float64 `json:"threshold"`
+	// Name of a level of the threshold package, e.g. "critical", its Min is used when Threshold is 0
+	Level string `json:"level,omitempty"`
+	// How long the comparison must hold before the alert fires
+	For time.Duration `json:"for"`
+	// Severity, e.g. Warning
+	Severity string `json:"severity"`
+}
+
+// Rules evaluated against every sample, set them before Start and read them with ActiveRules
+var Rules = []Rule{
+	{Name: "cpu-high", Metric: threshold.CPU, Level: "critical", For: 5 * time.Minute, Severity: Warning},
+	{Name: "memory-high", Metric: threshold.Memory, Level: "critical", For: 5 * time.Minute, Severity: Warning},
+	{Name: "disk-full", Metric: threshold.Disk, Level: "critical", For: time.Minute, Severity: Critical},
+}
+
+// Firing alerts are notified again after RepeatInterval, 0 notifies them once
+var RepeatInterval = 4 * time.Hour
+
+// Alert is a rule met by a subject, e.g. a mountpoint
+type Alert struct {
+	ID      int    `json:"id"`
+	Rule    Rule   `json:"rule"`
+	Subject string `json:"subject,omitempty"`
+	State   string `json:"state"`
+	// Last value of the metric and the threshold it was compared to
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	// When the rule was first met, fired and resolved
+	Since      time.Time `json:"since"`
+	FiredAt    time.Time `json:"firedAt,omitempty"`
+	ResolvedAt time.Time `json:"resolvedAt,omitempty"`
+	// Who acknowledged the alert and when, see Ack
+	AckedBy string    `json:"ackedBy,omitempty"`
+	AckedAt time.Time `json:"ackedAt,omitempty"`
+
+	notifiedAt time.Time
+}
+
+// Notification tells an alert fired, is still firing or resolved
+type Notification struct {
+	Alert
+	// Repeated notification of a firing alert
+	Repeat bool
+	// Snapshot the alert was evaluated against and when it was taken
+	Info sysinfo.SysInfo
+	At   time.Time
+}
+
+var alerts = map[string]*Alert{}
+var lastID int
+var lock sync.Mutex
+var notifications = make(chan Notification, 64)
+var startOnce sync.Once
+
+// Start evaluates the rules against every sample of the background sampler, it can be called many times
+func Start() {
+	startOnce.Do(func() {
+		lock.Lock()
+		var valid []Rule
+		for _, rule := range Rules {
+			if err := rule.Validate(); err != nil {
+				slog.Error(fmt.Sprintf("Status: ignoring alert %v", err))
+				continue
+			}
+			valid = append(valid, rule)
+		}
+		Rules = valid
+		lock.Unlock()
+		sysinfo.StartSampler()
+		samples, _ := sysinfo.Subscribe()
+		go func() {
+			for s := range samples {
+				Evaluate(s.SysInfo, s.At)
+			}
+		}()
+	})
+}
+
+// ActiveRules returns a copy of the rules being evaluated, without the invalid ones once Start is called.
+// Rules is replaced by Start, read it through ActiveRules while alerts may be running
+func ActiveRules() []Rule {
+	lock.Lock()
+	defer lock.Unlock()
+	return append([]Rule(nil), Rules...)
+}
+
+// Notifications receives the alerts firing and resolving
+func Notifications() <-chan Notification {
+	return notifications
+}
+
+// Alerts returns the pending and firing alerts, oldest first
+func Alerts() []Alert {
+	lock.Lock()
+	defer lock.Unlock()
+	result := make([]Alert, 0, len(alerts))
+	for _, a := range alerts {
+		result = append(result, *a)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// Evaluate checks the rules against a snapshot taken at at
+func Evaluate(info sysinfo.SysInfo, at time.Time) {
+	lock.Lock()
+	defer lock.Unlock()
+	seen := map[string]bool{}
+	for _, rule := range Rules {
+		values, ok := valuesOf(rule, info)
+		if !ok {
+			// The collector failed for some subjects, keep their alerts as they are
+			for key, a := range alerts {
+				if a.Rule.Name == rule.Name {
+					seen[key] = true
+				}
+			}
+		}
+		for subject, value := range values {
+			key := rule.Name + "|" + subject
+			seen[key] = true
+			limit := rule.threshold(subject)
+			a, exists := alerts[key]
+			if !compare(rule.Comparison, value, limit) {
+				if exists {
+					resolve(key, a, info, at)
+				}
+				continue
+			}
+			if !exists {
+				lastID++
+				a = &Alert{ID: lastID, Rule: rule, Subject: subject, State: Pending, Since: since(rule, subject, limit, at)}
+				alerts[key] = a
+			}
+			a.Rule, a.Value, a.Threshold = rule, value, limit
+			switch {
+			case a.State == Pending && at.Sub(a.Since) >= rule.For:
+				a.State, a.FiredAt = Firing, at
+				notify(a, false, info, at)
+			case a.State == Firing && a.AckedAt.IsZero() && RepeatInterval > 0 && at.Sub(a.notifiedAt) >= RepeatInterval:
+				notify(a, true, info, at)
+			}
+		}
+	}
+	// Rules removed and disks unmounted
+	for key, a := range alerts {
+		if !seen[key] {
+			resolve(key, a, info, at)
+		}
+	}
+}
+
+func resolve(key string, a *Alert, info sysinfo.SysInfo, at time.Time) {
+	delete(alerts, key)
+	if a.State == Firing {
+		a.State, a.ResolvedAt = Resolved, at
+		notify(a, false, info, at)
+	}
+}
+
+func notify(a *Alert, repeat bool, info sysinfo.SysInfo, at time.Time) {
+	a.notifiedAt = at
+	if Silenced(a.Rule.Name, at) {
+		return
+	}
+	select {
+	case notifications <- Notification{Alert: *a, Repeat: repeat, Info: info, At: at}:
+	default:
+		slog.Warn(fmt.Sprintf("Status: dropped the notification of alert %s, nobody is receiving them", a.Rule.Name))
+	}
+}
+
+// since returns when the rule started being met according to the history, so restarts do not reset For
+func since(rule Rule, subject string, limit float64, at time.Time) time.Time {
+	if rule.For <= 0 || !history.Enabled() {
+		return at
+	}
+	result := at
+	points := history.Query(at.Add(-rule.For-sysinfo.SampleInterval), at, 0)
+	for i := len(points) - 1; i >= 0; i-- {
+		p := points[i]
+		// A gap means the bot was down
+		if result.Sub(p.At) > 2*sysinfo.SampleInterval {
+			break
+		}
+		value, ok := pointValue(rule, subject, p)
+		if !ok || !compare(rule.Comparison, value, limit) {
+			break
+		}
+		result = p.At
+	}
+	return result
+}
+
+// pointValue returns the value of the rule's metric in a history point
+func pointValue(rule Rule, subject string, p history.Point) (float64, bool) {
+	switch rule.Metric {
+	case threshold.CPU:
+		return p.CPU, true
+	case threshold.Memory:
+		return p.Memory, true
+	case Load1:
+		return p.Load1, true
+	case Load5:
+		return p.Load5, true
+	case Load15:
+		return p.Load15, true
+	case threshold.Disk:
+		disk, ok := p.Disks[subject]
+		return disk.Percent, ok
+	}
+	return 0, false
+}
+
+// valuesOf returns the values of the rule's metric by subject, false if some subjects were not collected
+func valuesOf(rule Rule, info sysinfo.SysInfo) (map[string]float64, bool) {
+	switch rule.Metric {
+	case threshold.CPU:
+		if _, failed := info.Errors["cpu"]; failed {
+			return nil, false
+		}
+		return map[string]float64{"": info.CpuUsedPercent}, true
+	case threshold.Memory:
+		if _, failed := info.Errors["memory"]; failed {
+			return nil, false
+		}
+		return map[string]float64{"": info.MemUsedPercent}, true
+	case Load1, Load5, Load15:
+		if _, failed := info.Errors["load"]; failed {
+			return nil, false
+		}
+		load := map[string]float64{Load1: info.CpuLoad1, Load5: info.CpuLoad5, Load15: info.CpuLoad15}[rule.Metric]
+		return map[string]float64{"": load}, true
+	case threshold.Disk:
+		// The disks read are checked even when others failed
+		_, failed := info.Errors["disk"]
+		values := map[string]float64{}
+		for _, disk := range info.Disks {
+			if rule.Mount == "" || rule.Mount == disk.Name {
+				values[disk.Name] = disk.UsedPercent
+			}
+		}
+		return values, !failed
+	}
+	return nil, false
+}
+
+// Validate reports whether the rule names a known metric and comparison
+func (rule Rule) Validate() error {
+	switch rule.Metric {
+	case threshold.CPU, threshold.Memory, threshold.Disk, Load1, Load5, Load15:
+	default:
+		return fmt.Errorf("rule %s: unknown metric %q", rule.Name, rule.Metric)
+	}
+	switch rule.Comparison {
+	case "", Above, AboveOrEqual, Below, BelowOrEqual:
+	default:
+		return fmt.Errorf("rule %s: unknown comparison %q", rule.Name, rule.Comparison)
+	}
+	return nil
+}
+
+// threshold returns the threshold of the rule for a subject
+func (rule Rule) threshold(subject string) float64 {
+	if rule.Threshold != 0 || rule.Level == "" {
+		return rule.Threshold
+	}
+	levels := threshold.For(rule.Metric)
+	if rule.Metric == threshold.Disk {
+		levels = threshold.ForMount(subject)
+	}
+	for _, level := range levels {
+		if level.Name == rule.Level {
+			return level.Min
+		}
+	}
+	return rule.Threshold
+}
+
+func compare(comparison string, value float64, limit float64) bool {
+	switch comparison {
+	case AboveOrEqual:
+		return value >= limit
+	case Below:
+		return value < limit
+	case BelowOrEqual:
+		return value <= limit
+	}
+	return value > limit
+}
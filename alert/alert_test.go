@@ -0,0 +1,170 @@
+package alert
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+	"github.com/gonebot-dev/goneplugin-status/threshold"
+)
+
+// reset starts over with rules and no alerts, silences or notifications
+func reset(t *testing.T, rules ...Rule) {
+	lock.Lock()
+	defer lock.Unlock()
+	previous, repeat := Rules, RepeatInterval
+	t.Cleanup(func() {
+		lock.Lock()
+		defer lock.Unlock()
+		Rules, RepeatInterval = previous, repeat
+	})
+	Rules = rules
+	alerts = map[string]*Alert{}
+	SilencesFile = filepath.Join(t.TempDir(), "silences.json")
+	silencesLock.Lock()
+	loadSilences()
+	silences = map[string]Silence{}
+	silencesLock.Unlock()
+	drain()
+}
+
+// drain returns the states of the notifications sent since the last call, with "+repeat" for repeated ones
+func drain() (states []string) {
+	for {
+		select {
+		case n := <-notifications:
+			if n.Repeat {
+				n.State += "+repeat"
+			}
+			states = append(states, n.State)
+		default:
+			return
+		}
+	}
+}
+
+func stateOf(name string) string {
+	for _, a := range Alerts() {
+		if a.Rule.Name == name {
+			return a.State
+		}
+	}
+	return ""
+}
+
+func TestEvaluateHysteresis(t *testing.T) {
+	reset(t, Rule{Name: "cpu", Metric: threshold.CPU, Threshold: 80, For: 2 * time.Minute})
+	RepeatInterval = 10 * time.Minute
+	base := time.Now()
+	steps := []struct {
+		minute int
+		cpu    float64
+		state  string
+		notes  []string
+	}{
+		{0, 90, Pending, nil},
+		{1, 90, Pending, nil},
+		{2, 90, Firing, []string{Firing}},
+		{3, 95, Firing, nil},
+		{12, 95, Firing, []string{Firing + "+repeat"}},
+		{13, 50, "", []string{Resolved}},
+		// Never firing, so never notified
+		{14, 90, Pending, nil},
+		{15, 50, "", nil},
+	}
+	for _, step := range steps {
+		Evaluate(sysinfo.SysInfo{CpuUsedPercent: step.cpu}, base.Add(time.Duration(step.minute)*time.Minute))
+		if got := stateOf("cpu"); got != step.state {
+			t.Errorf("minute %d: state = %q, want %q", step.minute, got, step.state)
+		}
+		if got := drain(); len(got) != len(step.notes) || (len(got) > 0 && got[0] != step.notes[0]) {
+			t.Errorf("minute %d: notifications = %v, want %v", step.minute, got, step.notes)
+		}
+	}
+}
+
+func TestEvaluateAcked(t *testing.T) {
+	reset(t, Rule{Name: "cpu", Metric: threshold.CPU, Threshold: 80})
+	RepeatInterval = time.Minute
+	base := time.Now()
+	Evaluate(sysinfo.SysInfo{CpuUsedPercent: 90}, base)
+	if err := Ack(Alerts()[0].ID, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	Evaluate(sysinfo.SysInfo{CpuUsedPercent: 90}, base.Add(5*time.Minute))
+	Evaluate(sysinfo.SysInfo{CpuUsedPercent: 50}, base.Add(6*time.Minute))
+	if got := drain(); len(got) != 2 || got[0] != Firing || got[1] != Resolved {
+		t.Errorf("notifications = %v, want the firing and resolving ones only", got)
+	}
+}
+
+func TestEvaluateSilenced(t *testing.T) {
+	reset(t, Rule{Name: "cpu", Metric: threshold.CPU, Threshold: 80})
+	if err := Mute("cpu", time.Hour, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Mute("cpux", time.Hour, "admin"); err == nil {
+		t.Error("muting an unknown rule succeeded")
+	}
+	base := time.Now()
+	Evaluate(sysinfo.SysInfo{CpuUsedPercent: 90}, base)
+	if got := stateOf("cpu"); got != Firing {
+		t.Errorf("silenced state = %q, want %q", got, Firing)
+	}
+	if got := drain(); len(got) != 0 {
+		t.Errorf("silenced notifications = %v, want none", got)
+	}
+	// Once the silence ends, the resolution is notified again
+	Evaluate(sysinfo.SysInfo{CpuUsedPercent: 50}, base.Add(2*time.Hour))
+	if got := drain(); len(got) != 1 || got[0] != Resolved {
+		t.Errorf("notifications after the silence = %v, want %v", got, []string{Resolved})
+	}
+}
+
+func TestEvaluateDiskErrors(t *testing.T) {
+	reset(t, Rule{Name: "disk", Metric: threshold.Disk, Threshold: 80})
+	base := time.Now()
+	full := func(names ...string) (disks []sysinfo.DiskInfo) {
+		for _, name := range names {
+			disks = append(disks, sysinfo.DiskInfo{Name: name, UsedPercent: 90})
+		}
+		return
+	}
+	steps := []struct {
+		name   string
+		info   sysinfo.SysInfo
+		firing int
+	}{
+		{"both full", sysinfo.SysInfo{Disks: full("/", "/data")}, 2},
+		{"one unreadable", sysinfo.SysInfo{Disks: full("/"), Errors: map[string]string{"disk": "/data: timeout"}}, 2},
+		{"every disk unreadable", sysinfo.SysInfo{Errors: map[string]string{"disk": "timeout"}}, 2},
+		{"one unmounted", sysinfo.SysInfo{Disks: full("/")}, 1},
+	}
+	for i, step := range steps {
+		Evaluate(step.info, base.Add(time.Duration(i)*time.Minute))
+		if got := len(Alerts()); got != step.firing {
+			t.Errorf("%s: %d alerts, want %d", step.name, got, step.firing)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		ok   bool
+	}{
+		{Rule{Name: "cpu", Metric: threshold.CPU}, true},
+		{Rule{Name: "load", Metric: Load5, Comparison: BelowOrEqual}, true},
+		{Rule{Name: "typo", Metric: "cpuu"}, false},
+		{Rule{Name: "comparison", Metric: threshold.Memory, Comparison: "=>"}, false},
+	}
+	for _, test := range tests {
+		if err := test.rule.Validate(); (err == nil) != test.ok {
+			t.Errorf("%s: Validate() = %v, want ok %v", test.rule.Name, err, test.ok)
+		}
+		if _, ok := valuesOf(test.rule, sysinfo.SysInfo{}); test.rule.Metric == "cpuu" && ok {
+			t.Errorf("%s: an unknown metric has values", test.rule.Name)
+		}
+	}
+}
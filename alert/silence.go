@@ -0,0 +1,123 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SilencesFile keeps the silences across restarts
+var SilencesFile = filepath.Join("data", "status", "silences.json")
+
+// Silence mutes the notifications of a rule until a time
+type Silence struct {
+	Rule  string    `json:"rule"`
+	Until time.Time `json:"until"`
+	By    string    `json:"by,omitempty"`
+}
+
+var silences = map[string]Silence{}
+var silencesLock sync.Mutex
+var silencesOnce sync.Once
+
+// loadSilences reads SilencesFile once, the caller holds silencesLock
+func loadSilences() {
+	silencesOnce.Do(func() {
+		data, err := os.ReadFile(SilencesFile)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				slog.Error(fmt.Sprintf("Status: failed to read the silences: %v", err))
+			}
+			return
+		}
+		var list []Silence
+		if err := json.Unmarshal(data, &list); err != nil {
+			slog.Error(fmt.Sprintf("Status: failed to read the silences: %v", err))
+			return
+		}
+		for _, s := range list {
+			silences[s.Rule] = s
+		}
+	})
+}
+
+// saveSilences writes the silences still running to SilencesFile, the caller holds silencesLock
+func saveSilences() error {
+	data, _ := json.MarshalIndent(silenceList(time.Now()), "", "  ")
+	if err := os.MkdirAll(filepath.Dir(SilencesFile), 0o755); err != nil {
+		return err
+	}
+	tmp := SilencesFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, SilencesFile)
+}
+
+func silenceList(now time.Time) []Silence {
+	list := []Silence{}
+	for name, s := range silences {
+		if !s.Until.After(now) {
+			delete(silences, name)
+			continue
+		}
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Rule < list[j].Rule })
+	return list
+}
+
+// Mute silences a rule for d, a d of 0 or less lifts its silence
+func Mute(rule string, d time.Duration, by string) error {
+	found := false
+	for _, r := range ActiveRules() {
+		found = found || r.Name == rule
+	}
+	if !found {
+		return fmt.Errorf("unknown rule %q", rule)
+	}
+	silencesLock.Lock()
+	defer silencesLock.Unlock()
+	loadSilences()
+	if d <= 0 {
+		delete(silences, rule)
+	} else {
+		silences[rule] = Silence{Rule: rule, Until: time.Now().Add(d), By: by}
+	}
+	return saveSilences()
+}
+
+// Silences returns the silences still running, by rule name
+func Silences() []Silence {
+	silencesLock.Lock()
+	defer silencesLock.Unlock()
+	loadSilences()
+	return silenceList(time.Now())
+}
+
+// Silenced reports whether a rule is silenced at a time
+func Silenced(rule string, at time.Time) bool {
+	silencesLock.Lock()
+	defer silencesLock.Unlock()
+	loadSilences()
+	s, ok := silences[rule]
+	return ok && s.Until.After(at)
+}
+
+// Ack acknowledges a pending or firing alert, it is not notified again until it resolves
+func Ack(id int, by string) error {
+	lock.Lock()
+	defer lock.Unlock()
+	for _, a := range alerts {
+		if a.ID == id {
+			a.AckedBy, a.AckedAt = by, time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("no alert #%d", id)
+}
@@ -0,0 +1,36 @@
+package alert
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/threshold"
+)
+
+// Start replaces Rules and keeps evaluating in the background, so this runs after the other tests
+func TestMuteDuringStart(t *testing.T) {
+	reset(t, Rule{Name: "cpu", Metric: threshold.CPU, Level: "critical", Severity: Warning})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		Start()
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if err := Mute("cpu", time.Minute, "admin"); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	if !Silenced("cpu", time.Now()) {
+		t.Error("cpu is not silenced")
+	}
+	if err := Mute("missing", time.Minute, "admin"); err == nil {
+		t.Error("muting an unknown rule did not fail")
+	}
+}
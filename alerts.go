@@ -0,0 +1,234 @@
+package status
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gonebot-dev/gonebot/message"
+	"github.com/gonebot-dev/goneplugin-status/alert"
+	"github.com/gonebot-dev/goneplugin-status/format"
+	"github.com/gonebot-dev/goneplugin-status/i18n"
+	"github.com/gonebot-dev/goneplugin-status/renderer"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+	"github.com/gonebot-dev/goneplugin-status/threshold"
+)
+
+// Evaluate the rules of the alert package and notify AlertUsers and AlertGroups
+var EnableAlerts = false
+
+// User IDs and group IDs alerts are sent to
+var AlertUsers = []string{}
+var AlertGroups = []string{}
+
+// Attach the card of the affected section to alert messages
+var AlertCard = true
+
+// User IDs allowed to list, silence and acknowledge alerts
+var Admins = []string{}
+
+// startAlerts turns alert notifications into messages for every target
+func startAlerts() {
+	alert.Start()
+	go func() {
+		for n := range alert.Notifications() {
+			for _, target := range alertTargets() {
+				outbox <- alertMessage(n, target)
+			}
+		}
+	}()
+}
+
+func alertTargets() (targets []message.Message) {
+	for _, user := range AlertUsers {
+		targets = append(targets, targetOf(user, ""))
+	}
+	for _, group := range AlertGroups {
+		targets = append(targets, targetOf("", group))
+	}
+	return
+}
+
+func alertMessage(n alert.Notification, target message.Message) message.Message {
+	msg := replyTo(target)
+	loc := localeOf(target)
+	redaction := redactionOf(target)
+
+	info, subject := subjectOf(n.Alert, n.Info, redaction)
+	condition := conditionOf(loc, n.Alert, subject)
+	switch {
+	case n.State == alert.Resolved:
+		msg.AddTextSegment(i18n.T(loc, "alert.resolved", n.Rule.Name, format.Duration(loc, n.ResolvedAt.Sub(n.FiredAt)), condition))
+	case n.Repeat:
+		msg.AddTextSegment(i18n.T(loc, "alert.repeat", n.Rule.Severity, n.Rule.Name, format.Duration(loc, n.At.Sub(n.FiredAt)), condition))
+	default:
+		msg.AddTextSegment(i18n.T(loc, "alert.firing", n.Rule.Severity, n.Rule.Name, condition))
+	}
+
+	if AlertCard {
+		opts := renderer.Options{
+			Locale:   loc,
+			Encoding: Encoding,
+			Scale:    Scale,
+			Width:    Width,
+			Columns:  Columns,
+			Section:  sectionOf(n.Rule.Metric),
+		}
+		segment, err := imageSegment(renderer.RenderImage(info, opts), opts.Encoding)
+		if err != nil {
+			slog.Error(fmt.Sprintf("Status: alert %s: %v", n.Rule.Name, err))
+		} else {
+			msg.AddImageSegment(segment)
+		}
+	}
+	return msg
+}
+
+// subjectOf returns the snapshot redacted and only holding the disk of a disk alert, and the alert's subject redacted
+func subjectOf(a alert.Alert, info sysinfo.SysInfo, redaction sysinfo.Redaction) (sysinfo.SysInfo, string) {
+	redacted := info.Redact(redaction)
+	if a.Rule.Metric != threshold.Disk {
+		return redacted, a.Subject
+	}
+	for i, disk := range info.Disks {
+		if disk.Name == a.Subject {
+			redacted.Disks = redacted.Disks[i : i+1]
+			return redacted, redacted.Disks[0].Name
+		}
+	}
+	return redacted, a.Subject
+}
+
+// conditionOf describes the rule of an alert, e.g. `Disk "/" 95.0% > 80.0%`
+func conditionOf(loc string, a alert.Alert, subject string) string {
+	return fmt.Sprintf("%s %s %s %s", i18n.T(loc, "alert."+a.Rule.Metric, subject),
+		alertValue(loc, a.Rule.Metric, a.Value), comparisonOf(a.Rule), alertValue(loc, a.Rule.Metric, a.Threshold))
+}
+
+func alertValue(loc string, metric string, v float64) string {
+	switch metric {
+	case alert.Load1, alert.Load5, alert.Load15:
+		return i18n.Number(loc, v, 2)
+	}
+	return format.Percent(loc, v)
+}
+
+func comparisonOf(rule alert.Rule) string {
+	if rule.Comparison == "" {
+		return alert.Above
+	}
+	return rule.Comparison
+}
+
+func sectionOf(metric string) string {
+	switch metric {
+	case threshold.Memory:
+		return renderer.SectionMemory
+	case threshold.Disk:
+		return renderer.SectionDisk
+	}
+	return renderer.SectionCPU
+}
+
+// alertCommands handle "status alerts", "status silence <rule> <duration>" and "status ack <id>"
+var alertCommands = map[string]func(msg message.Message, args []string, loc string) string{
+	"alerts":  listAlerts,
+	"silence": silenceAlert,
+	"ack":     ackAlert,
+}
+
+func isAdmin(msg message.Message) bool {
+	for _, admin := range Admins {
+		if admin == msg.SenderID {
+			return true
+		}
+	}
+	return false
+}
+
+func listAlerts(msg message.Message, args []string, loc string) string {
+	alerts := alert.Alerts()
+	silences := alert.Silences()
+	if len(alerts) == 0 && len(silences) == 0 {
+		return i18n.T(loc, "alert.none")
+	}
+	lines := []string{}
+	now := time.Now()
+	if len(alerts) > 0 {
+		lines = append(lines, i18n.T(loc, "alert.list"))
+		info, _ := sysinfo.Latest()
+		for _, a := range alerts {
+			_, subject := subjectOf(a, info, redactionOf(msg))
+			line := i18n.T(loc, "alert.item", a.ID, a.Rule.Severity, a.Rule.Name, i18n.T(loc, "alert.state."+a.State),
+				format.Duration(loc, now.Sub(a.Since)), conditionOf(loc, a, subject))
+			if a.AckedBy != "" {
+				line += i18n.T(loc, "alert.acked", a.AckedBy)
+			}
+			if alert.Silenced(a.Rule.Name, now) {
+				line += i18n.T(loc, "alert.silenced")
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(silences) > 0 {
+		lines = append(lines, i18n.T(loc, "alert.silences"))
+		for _, s := range silences {
+			lines = append(lines, i18n.T(loc, "alert.silence.item", s.Rule, format.Duration(loc, s.Until.Sub(now))))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func silenceAlert(msg message.Message, args []string, loc string) string {
+	if len(args) < 2 {
+		return i18n.T(loc, "alert.silence.usage", TriggerCommand)
+	}
+	d, err := parseDuration(args[1])
+	if err != nil {
+		return i18n.T(loc, "alert.silence.usage", TriggerCommand)
+	}
+	if err := alert.Mute(args[0], d, msg.SenderID); err != nil {
+		names := []string{}
+		for _, rule := range alert.ActiveRules() {
+			names = append(names, rule.Name)
+		}
+		return i18n.T(loc, "alert.rule.unknown", args[0], strings.Join(names, ", "))
+	}
+	if d <= 0 {
+		return i18n.T(loc, "alert.silence.lifted", args[0])
+	}
+	return i18n.T(loc, "alert.silence.set", args[0], format.Duration(loc, d))
+}
+
+func ackAlert(msg message.Message, args []string, loc string) string {
+	if len(args) < 1 {
+		return i18n.T(loc, "alert.ack.usage", TriggerCommand)
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return i18n.T(loc, "alert.ack.usage", TriggerCommand)
+	}
+	if err := alert.Ack(id, msg.SenderID); err != nil {
+		return i18n.T(loc, "alert.ack.unknown", id)
+	}
+	return i18n.T(loc, "alert.ack.done", id)
+}
+
+// parseDuration parses a time.Duration, also accepting days such as "1d"
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		return time.Duration(n) * 24 * time.Hour, err
+	}
+	return time.ParseDuration(s)
+}
+
+// muted returns the names of the silenced rules
+func muted() (names []string) {
+	for _, s := range alert.Silences() {
+		names = append(names, s.Rule)
+	}
+	return
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gonebot-dev/gonebot/adapter"
+	"github.com/gonebot-dev/goneplugin-status/renderer"
+	"github.com/gonebot-dev/goneplugin-status/server"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+	"github.com/gonebot-dev/goneplugin-status/timing"
+)
+
+// AdapterCheck reports whether the adapter is connected. Gonebot only tells which adapter is loaded,
+// so it also takes an adapter receiving nothing for QuietAfter as disconnected, adapters knowing more can replace it
+var AdapterCheck = func() error {
+	if adapter.GetCurrentAdatper().Name == "" {
+		return errors.New("no adapter loaded")
+	}
+	return quiet(sysinfo.History(), QuietAfter)
+}
+
+// An adapter receiving no message for QuietAfter is taken as disconnected, 0 disables it for bots often idle.
+// Only the sampler's memory is looked at, see sysinfo.HistorySize
+var QuietAfter time.Duration
+
+// Snapshots older than StaleAfter sample intervals mean the sampler is stuck
+var StaleAfter = 3
+
+// Images younger than CacheTTL are reused, shared with the status command, 0 disables the cache
+var CacheTTL = 5 * time.Second
+
+// Register adds the API and health endpoints to the built-in server and starts the sampler.
+// Snapshots are redacted with redaction, images rendered with opts as PNG
+func Register(opts renderer.Options, redaction sysinfo.Redaction) {
+	sysinfo.StartSampler()
+	server.Handle("/api/status", server.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, at := sysinfo.Latest()
+		writeJSON(w, http.StatusOK, sysinfo.Sample{SysInfo: info.Redact(redaction), At: at})
+	})))
+	server.Handle("/api/status/image", server.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o := opts
+		o.Encoding = renderer.Encoding{Format: renderer.PNG}
+		if view := r.URL.Query().Get("view"); view != "" {
+			o.View = view
+		}
+		if locale := r.URL.Query().Get("locale"); locale != "" {
+			o.Locale = locale
+		}
+		render := func() image.Image {
+			info, _ := sysinfo.Latest()
+			return renderer.RenderImage(info.Redact(redaction), o)
+		}
+		var img image.Image
+		if CacheTTL > 0 {
+			var at time.Time
+			img, at = renderer.Cached(fmt.Sprintf("%s|%+v", o.CacheKey(), redaction), CacheTTL, render)
+			if age := time.Since(at); age >= time.Second {
+				img = renderer.DrawAge(img, age, o)
+			}
+		} else {
+			img = render()
+		}
+		encoded, err := renderer.Encode(img, o.Encoding)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if encoded.OverBudget {
+			slog.Warn(fmt.Sprintf("Status: the image is %d bytes at the lowest quality and scale, over the budget of %d", encoded.Size(), o.Encoding.MaxBytes))
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(encoded.Data)
+	})))
+	server.Handle("/api/status/timings", server.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, timing.Snapshot())
+	})))
+	server.Handle("/healthz", healthz())
+	server.Handle("/readyz", readyz(redaction))
+}
+
+// latest is the snapshot the health checks look at, replaced in tests
+var latest = sysinfo.Latest
+
+func healthz() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, at := latest()
+		respond(w, map[string]error{"sampler": fresh(at)}, nil)
+	})
+}
+
+func readyz(redaction sysinfo.Redaction) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, at := latest()
+		checks := map[string]error{
+			"sampler": fresh(at),
+			"adapter": AdapterCheck(),
+		}
+		respond(w, checks, info.Redact(redaction).Errors)
+	})
+}
+
+// fresh fails if the last sample is too old
+func fresh(at time.Time) error {
+	if age := time.Since(at); age > time.Duration(StaleAfter)*sysinfo.SampleInterval {
+		return fmt.Errorf("last sample is %s old", age.Round(time.Second))
+	}
+	return nil
+}
+
+// quiet fails if the received messages did not change in the samples of the last d
+func quiet(samples []sysinfo.Sample, d time.Duration) error {
+	if d <= 0 || len(samples) == 0 {
+		return nil
+	}
+	last := samples[len(samples)-1]
+	if last.At.Sub(samples[0].At) < d {
+		// Too few samples to tell
+		return nil
+	}
+	for i := len(samples) - 2; i >= 0 && last.At.Sub(samples[i].At) <= d; i-- {
+		if samples[i].ReceivedTotal != last.ReceivedTotal {
+			return nil
+		}
+	}
+	return fmt.Errorf("no message received in %s", d)
+}
+
+// respond writes the checks and the collector errors, with 503 if any failed
+func respond(w http.ResponseWriter, checks map[string]error, collectors map[string]string) {
+	code := http.StatusOK
+	status := "ok"
+	if len(collectors) > 0 {
+		code = http.StatusServiceUnavailable
+		status = "unavailable"
+	}
+	body := map[string]string{}
+	for name, err := range checks {
+		body[name] = "ok"
+		if err != nil {
+			body[name] = err.Error()
+			code = http.StatusServiceUnavailable
+			status = "unavailable"
+		}
+	}
+	result := map[string]any{"status": status, "checks": body}
+	if len(collectors) > 0 {
+		result["collectors"] = collectors
+	}
+	writeJSON(w, code, result)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
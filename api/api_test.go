@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+)
+
+func TestReadyz(t *testing.T) {
+	defer func(l func() (sysinfo.SysInfo, time.Time), c func() error) { latest, AdapterCheck = l, c }(latest, AdapterCheck)
+	tests := []struct {
+		name    string
+		errors  map[string]string
+		adapter error
+		age     time.Duration
+		want    int
+		failing string
+	}{
+		{name: "ready", want: http.StatusOK},
+		{name: "collector failing", errors: map[string]string{"disk": "permission denied"}, want: http.StatusServiceUnavailable},
+		{name: "adapter down", adapter: errors.New("no message received in 10m0s"), want: http.StatusServiceUnavailable, failing: "adapter"},
+		{name: "sampler stuck", age: time.Hour, want: http.StatusServiceUnavailable, failing: "sampler"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latest = func() (sysinfo.SysInfo, time.Time) {
+				return sysinfo.SysInfo{Errors: tt.errors}, time.Now().Add(-tt.age)
+			}
+			AdapterCheck = func() error { return tt.adapter }
+			w := httptest.NewRecorder()
+			readyz(sysinfo.Redaction{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.want {
+				t.Errorf("code = %d, want %d", w.Code, tt.want)
+			}
+			var body struct {
+				Status     string            `json:"status"`
+				Checks     map[string]string `json:"checks"`
+				Collectors map[string]string `json:"collectors"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if want := map[bool]string{true: "ok", false: "unavailable"}[tt.want == http.StatusOK]; body.Status != want {
+				t.Errorf("status = %q, want %q", body.Status, want)
+			}
+			for name, result := range body.Checks {
+				if (name == tt.failing) == (result == "ok") {
+					t.Errorf("check %s = %q", name, result)
+				}
+			}
+			if len(body.Collectors) != len(tt.errors) {
+				t.Errorf("collectors = %v, want %v", body.Collectors, tt.errors)
+			}
+		})
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	start := time.Date(2026, 1, 7, 10, 0, 0, 0, time.UTC)
+	// samples are a minute apart with the received totals given
+	samples := func(received ...int) (s []sysinfo.Sample) {
+		for i, r := range received {
+			s = append(s, sysinfo.Sample{SysInfo: sysinfo.SysInfo{ReceivedTotal: r}, At: start.Add(time.Duration(i) * time.Minute)})
+		}
+		return
+	}
+	tests := []struct {
+		name    string
+		samples []sysinfo.Sample
+		after   time.Duration
+		quiet   bool
+	}{
+		{name: "disabled", samples: samples(1, 1, 1, 1), after: 0},
+		{name: "no samples", after: time.Minute},
+		{name: "too few samples", samples: samples(1, 1, 1), after: 3 * time.Minute},
+		{name: "receiving", samples: samples(1, 1, 2, 2), after: 2 * time.Minute},
+		{name: "received before the window", samples: samples(1, 2, 2, 2), after: 2 * time.Minute, quiet: true},
+		{name: "quiet", samples: samples(5, 5, 5, 5), after: 3 * time.Minute, quiet: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := quiet(tt.samples, tt.after); (err != nil) != tt.quiet {
+				t.Errorf("quiet() = %v, want quiet %v", err, tt.quiet)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package dashboard
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/history"
+	"github.com/gonebot-dev/goneplugin-status/renderer"
+	"github.com/gonebot-dev/goneplugin-status/server"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+)
+
+//go:embed assets
+var assetsFS embed.FS
+
+// Path the dashboard is served under
+var Path = "/dashboard/"
+
+// view is a snapshot laid out like the card, so the page only draws it
+type view struct {
+	At     time.Time            `json:"at"`
+	Panels []renderer.PanelView `json:"panels"`
+	Errors map[string]string    `json:"errors,omitempty"`
+}
+
+// point is a sample in the history charts
+type point struct {
+	At       time.Time `json:"at"`
+	CPU      float64   `json:"cpu"`
+	Memory   float64   `json:"memory"`
+	Load     float64   `json:"load"`
+	Received int64     `json:"received"`
+	Sent     int64     `json:"sent"`
+}
+
+type state struct {
+	View    view    `json:"view"`
+	History []point `json:"history"`
+	// Points the charts keep
+	Size int `json:"size"`
+	// Whether ranges longer than the sampler's memory can be picked
+	Ranges bool `json:"ranges"`
+}
+
+// Ranges of the charts besides the sampler's memory
+var ranges = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"1y":  365 * 24 * time.Hour,
+}
+
+type event struct {
+	View  view  `json:"view"`
+	Point point `json:"point"`
+}
+
+// Register adds the dashboard to the built-in server and starts the sampler.
+// Snapshots are redacted with redaction and labelled in locale
+func Register(locale string, redaction sysinfo.Redaction) {
+	sysinfo.StartSampler()
+	assets, _ := fs.Sub(assetsFS, "assets")
+	files := http.StripPrefix(Path, http.FileServer(http.FS(assets)))
+	server.Handle(Path, server.Protect(files))
+	server.Handle(Path+"font.ttf", server.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The icons of the card are in its font
+		w.Header().Set("Content-Type", "font/ttf")
+		w.Header().Set("Cache-Control", "max-age=86400")
+		w.Write(renderer.EmbeddedFont())
+	})))
+	server.Handle(Path+"state", server.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st := state{History: []point{}, Size: sysinfo.HistorySize}
+		if d, ok := ranges[r.URL.Query().Get("range")]; ok && history.Enabled() {
+			// Longer ranges come from the history store, about Size points each
+			now := time.Now()
+			for _, p := range history.Query(now.Add(-d), now, d/time.Duration(st.Size)) {
+				st.History = append(st.History, point{
+					At:       p.At,
+					CPU:      p.CPU,
+					Memory:   p.Memory,
+					Load:     p.Load1,
+					Received: p.Received,
+					Sent:     p.Sent,
+				})
+			}
+		} else {
+			for _, s := range sysinfo.History() {
+				st.History = append(st.History, pointOf(s))
+			}
+		}
+		st.Ranges = history.Enabled()
+		info, at := sysinfo.Latest()
+		st.View = viewOf(sysinfo.Sample{SysInfo: info, At: at}, locale, redaction)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(st)
+	})))
+	server.Handle(Path+"events", server.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		samples, unsubscribe := sysinfo.Subscribe()
+		defer unsubscribe()
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Accel-Buffering", "no")
+		fmt.Fprintf(w, "retry: %d\n\n", sysinfo.SampleInterval.Milliseconds())
+		flusher.Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case s := <-samples:
+				data, _ := json.Marshal(event{View: viewOf(s, locale, redaction), Point: pointOf(s)})
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				flusher.Flush()
+			}
+		}
+	})))
+}
+
+func pointOf(s sysinfo.Sample) point {
+	p := point{
+		At:       s.At,
+		CPU:      s.CpuUsedPercent,
+		Memory:   s.MemUsedPercent,
+		Load:     s.CpuLoad1,
+		Received: int64(s.ReceivedTotal),
+		Sent:     int64(s.SentTotal),
+	}
+	// Same counters as the history's, so rates do not jump between them
+	if history.Enabled() {
+		p.Received, p.Sent = history.Totals(s.SysInfo)
+	}
+	return p
+}
+
+// viewOf lays a sample out with the panels of the card
+func viewOf(s sysinfo.Sample, loc string, redaction sysinfo.Redaction) view {
+	info := s.Redact(redaction)
+	return view{At: s.At, Panels: renderer.Panels(info, renderer.Options{Locale: loc}), Errors: info.Errors}
+}
@@ -0,0 +1,155 @@
+package delivery
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/renderer"
+	"github.com/gonebot-dev/goneplugin-status/server"
+	"github.com/gonebot-dev/goneplugin-status/timing"
+)
+
+// Delivery modes
+const (
+	// Base64 inlines the image as "base64://..."
+	Base64 = "base64"
+	// File writes the image to Dir and passes a "file://" path
+	File = "file"
+	// HTTP writes the image to Dir and passes an "http://" URL served by the built-in server
+	HTTP = "http"
+)
+
+// Dir is where images are written
+var Dir = filepath.Join(os.TempDir(), "goneplugin-status")
+
+// Cleanup policy of Dir, files older than MaxAge or beyond the MaxFiles newest ones are removed
+var MaxAge = 10 * time.Minute
+var MaxFiles = 64
+
+// BaseURL is how the adapter reaches the built-in server, the server's address is used when empty
+var BaseURL = ""
+
+// Path the images are served at
+const imagePath = "/images/"
+
+var extensions = map[string]string{
+	renderer.PNG:        "png",
+	renderer.PalettePNG: "png",
+	renderer.JPEG:       "jpg",
+}
+
+var lock sync.Mutex
+var serving sync.Once
+
+// Deliver turns an encoded image into something an image segment accepts
+func Deliver(mode string, encoded renderer.Encoded) (string, error) {
+	if mode == "" {
+		mode = Base64
+	}
+	defer timing.Start(timing.Deliver + "." + mode)()
+	switch mode {
+	case "", Base64:
+		return "base64://" + base64.StdEncoding.EncodeToString(encoded.Data), nil
+	case File:
+		path, err := write(encoded)
+		if err != nil {
+			return "", err
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+		// Drive letters need a leading slash, file:///C:/...
+		p := filepath.ToSlash(abs)
+		if !strings.HasPrefix(p, "/") {
+			p = "/" + p
+		}
+		return (&url.URL{Scheme: "file", Path: p}).String(), nil
+	case HTTP:
+		serving.Do(func() {
+			files := http.StripPrefix(imagePath, http.FileServer(http.Dir(Dir)))
+			server.Handle(imagePath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// No directory listing
+				if strings.HasSuffix(r.URL.Path, "/") {
+					http.NotFound(w, r)
+					return
+				}
+				files.ServeHTTP(w, r)
+			}))
+		})
+		if err := server.Start(); err != nil {
+			return "", err
+		}
+		path, err := write(encoded)
+		if err != nil {
+			return "", err
+		}
+		base := BaseURL
+		if base == "" {
+			base = "http://" + server.Addr
+		}
+		return strings.TrimSuffix(base, "/") + imagePath + filepath.Base(path), nil
+	}
+	return "", fmt.Errorf("unknown delivery mode %q", mode)
+}
+
+// write stores the image named by its content hash and cleans Dir up
+func write(encoded renderer.Encoded) (string, error) {
+	lock.Lock()
+	defer lock.Unlock()
+	if err := os.MkdirAll(Dir, 0o755); err != nil {
+		return "", err
+	}
+	ext, ok := extensions[encoded.Format]
+	if !ok {
+		ext = encoded.Format
+	}
+	sum := sha256.Sum256(encoded.Data)
+	path := filepath.Join(Dir, hex.EncodeToString(sum[:12])+"."+ext)
+	if err := os.WriteFile(path, encoded.Data, 0o644); err != nil {
+		return "", err
+	}
+	cleanup(path)
+	return path, nil
+}
+
+// cleanup applies the policy to Dir, keeping the file just written
+func cleanup(keep string) {
+	entries, err := os.ReadDir(Dir)
+	if err != nil {
+		return
+	}
+	type file struct {
+		path    string
+		modTime time.Time
+	}
+	var files []file
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, file{filepath.Join(Dir, entry.Name()), info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	keepAbs, _ := filepath.Abs(keep)
+	for i, f := range files {
+		abs, _ := filepath.Abs(f.path)
+		if abs == keepAbs {
+			continue
+		}
+		if (MaxFiles > 0 && i >= MaxFiles) || (MaxAge > 0 && time.Since(f.modTime) > MaxAge) {
+			os.Remove(f.path)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package format
+
+import (
+	"math"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/i18n"
+)
+
+// Units is a family of byte units
+type Units int
+
+const (
+	// IEC units, powers of 1024: KiB, MiB, GiB...
+	IEC Units = iota
+	// SI units, powers of 1000: KB, MB, GB...
+	SI
+)
+
+// ByteUnits is used by Bytes and Rate
+var ByteUnits = IEC
+
+var iecNames = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+var siNames = []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}
+
+// precision keeps three significant digits: 1.23, 12.3, 123
+func precision(v float64) int {
+	if v < 10 {
+		return 2
+	} else if v < 100 {
+		return 1
+	}
+	return 0
+}
+
+// rounded rounds v to three significant digits and returns its decimals, rounding first so 9.996 gives 10.0, not 10.00
+func rounded(v float64) (float64, int) {
+	prec := precision(v)
+	pow := math.Pow10(prec)
+	r := math.Round(v*pow) / pow
+	if p := precision(r); p != prec {
+		prec, pow = p, math.Pow10(p)
+		r = math.Round(v*pow) / pow
+	}
+	return r, prec
+}
+
+// scale picks the largest unit that keeps v at least 1 once rounded, so 1023.9 KiB gives 1.00 MiB
+func scale(v float64) (float64, string) {
+	base, names := 1024.0, iecNames
+	if ByteUnits == SI {
+		base, names = 1000.0, siNames
+	}
+	i := 0
+	for i < len(names)-1 {
+		if r, _ := rounded(v); r < base {
+			break
+		}
+		v /= base
+		i++
+	}
+	return v, names[i]
+}
+
+// Bytes formats a byte count with an auto-scaled unit, e.g. 1.5 GiB or 300 TiB
+func Bytes(locale string, b uint64) string {
+	v, unit := scale(float64(b))
+	if unit == "B" {
+		return i18n.Number(locale, v, 0) + " " + unit
+	}
+	v, prec := rounded(v)
+	return i18n.Number(locale, v, prec) + " " + unit
+}
+
+// Rate formats bytes per second with an auto-scaled unit, e.g. 12.3 MiB/s
+func Rate(locale string, bps float64) string {
+	if bps < 0 {
+		bps = 0
+	}
+	v, unit := scale(bps)
+	v, prec := rounded(v)
+	return i18n.Number(locale, v, prec) + " " + unit + "/s"
+}
+
+// Percent formats a percentage with one decimal, e.g. 42.1%
+func Percent(locale string, p float64) string {
+	return i18n.T(locale, "percent", i18n.Number(locale, p, 1))
+}
+
+// Duration formats a duration with its two largest units, e.g. 3d 4h, 12m or 45s
+func Duration(locale string, d time.Duration) string {
+	seconds := int64(d / time.Second)
+	parts := []struct {
+		key   string
+		value int64
+	}{
+		{"duration.days", seconds / 86400},
+		{"duration.hours", seconds % 86400 / 3600},
+		{"duration.minutes", seconds % 3600 / 60},
+		{"duration.seconds", seconds % 60},
+	}
+
+	// Skip the leading zero units, then keep at most two
+	first := 0
+	for first < len(parts)-1 && parts[first].value == 0 {
+		first++
+	}
+	result := i18n.N(locale, parts[first].key, parts[first].value, parts[first].value)
+	if next := first + 1; next < len(parts) && parts[next].value != 0 {
+		result += i18n.T(locale, "duration.separator") + i18n.N(locale, parts[next].key, parts[next].value, parts[next].value)
+	}
+	return result
+}
+
+// Latency formats a short duration with three significant digits, e.g. 0.85ms, 412ms or 1.23s
+func Latency(locale string, d time.Duration) string {
+	// 999.7ms reads 1.00s
+	if ms, prec := rounded(float64(d) / float64(time.Millisecond)); ms < 1000 {
+		return i18n.T(locale, "latency.ms", i18n.Number(locale, ms, prec))
+	}
+	s, prec := rounded(d.Seconds())
+	return i18n.T(locale, "latency.s", i18n.Number(locale, s, prec))
+}
@@ -0,0 +1,98 @@
+package format
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		units Units
+		b     uint64
+		want  string
+	}{
+		{IEC, 0, "0 B"},
+		{IEC, 1023, "1,023 B"},
+		{IEC, 1024, "1.00 KiB"},
+		{IEC, 1536, "1.50 KiB"},
+		{IEC, 10234, "9.99 KiB"},
+		{IEC, 10235, "10.0 KiB"},
+		{IEC, 102297, "99.9 KiB"},
+		{IEC, 102358, "100 KiB"},
+		{IEC, 1047552, "1,023 KiB"},
+		{IEC, 1048575, "1.00 MiB"},
+		{IEC, 3 << 30, "3.00 GiB"},
+		{SI, 999, "999 B"},
+		{SI, 999_600, "1.00 MB"},
+		{SI, 1_500_000_000, "1.50 GB"},
+	}
+	defer func(units Units) { ByteUnits = units }(ByteUnits)
+	for _, test := range tests {
+		ByteUnits = test.units
+		if got := Bytes("en", test.b); got != test.want {
+			t.Errorf("Bytes(%d) = %q, want %q", test.b, got, test.want)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		bps  float64
+		want string
+	}{
+		{-5, "0.00 B/s"},
+		{0.5, "0.50 B/s"},
+		{9.996, "10.0 B/s"},
+		{1023.7, "1.00 KiB/s"},
+		{12.3 * 1024 * 1024, "12.3 MiB/s"},
+	}
+	for _, test := range tests {
+		if got := Rate("en", test.bps); got != test.want {
+			t.Errorf("Rate(%v) = %q, want %q", test.bps, got, test.want)
+		}
+	}
+}
+
+func TestPercent(t *testing.T) {
+	if got := Percent("en", 42.06); got != "42.1%" {
+		t.Errorf("Percent(42.06) = %q, want %q", got, "42.1%")
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		locale string
+		d      time.Duration
+		want   string
+	}{
+		{"en", 0, "0s"},
+		{"en", 45 * time.Second, "45s"},
+		{"en", 12*time.Minute + 3*time.Second, "12m 3s"},
+		{"en", 3*24*time.Hour + 4*time.Hour + 5*time.Minute, "3d 4h"},
+		{"en", 2 * time.Hour, "2h"},
+		{"zh-CN", 90 * time.Minute, "1小时30分"},
+	}
+	for _, test := range tests {
+		if got := Duration(test.locale, test.d); got != test.want {
+			t.Errorf("Duration(%q, %v) = %q, want %q", test.locale, test.d, got, test.want)
+		}
+	}
+}
+
+func TestLatency(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{850 * time.Microsecond, "0.85ms"},
+		{412 * time.Millisecond, "412ms"},
+		{9996 * time.Microsecond, "10.0ms"},
+		{999700 * time.Microsecond, "1.00s"},
+		{1234 * time.Millisecond, "1.23s"},
+	}
+	for _, test := range tests {
+		if got := Latency("en", test.d); got != test.want {
+			t.Errorf("Latency(%v) = %q, want %q", test.d, got, test.want)
+		}
+	}
+}
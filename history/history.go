@@ -0,0 +1,306 @@
+package history
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+)
+
+// Dir holds a directory of segments for every tier
+var Dir = filepath.Join("data", "status", "history")
+
+// Tier keeps points of a resolution for some time
+type Tier struct {
+	Name string
+	// Points are averaged over Step, 0 keeps every sample
+	Step      time.Duration
+	Retention time.Duration
+	// Segments are files covering SegmentSpan each, deleted once out of the retention
+	SegmentSpan time.Duration
+}
+
+// Tiers from the finest to the coarsest
+var Tiers = []Tier{
+	{Name: "raw", Step: 0, Retention: 24 * time.Hour, SegmentSpan: time.Hour},
+	{Name: "1m", Step: time.Minute, Retention: 7 * 24 * time.Hour, SegmentSpan: 24 * time.Hour},
+	{Name: "1h", Step: time.Hour, Retention: 365 * 24 * time.Hour, SegmentSpan: 30 * 24 * time.Hour},
+}
+
+// DiskPoint is the usage of a partition
+type DiskPoint struct {
+	Used    uint64  `json:"used"`
+	Percent float64 `json:"pct"`
+}
+
+// Point is a sample, or the aggregate of the samples of a step
+type Point struct {
+	At time.Time `json:"t"`
+	// Samples aggregated
+	N int `json:"n"`
+	// Averages, with the minima and maxima of CPU and memory
+	CPU       float64              `json:"cpu"`
+	CPUMin    float64              `json:"cpuMin"`
+	CPUMax    float64              `json:"cpuMax"`
+	Memory    float64              `json:"mem"`
+	MemoryMin float64              `json:"memMin"`
+	MemoryMax float64              `json:"memMax"`
+	Load1     float64              `json:"load1"`
+	Load5     float64              `json:"load5"`
+	Load15    float64              `json:"load15"`
+	Disks     map[string]DiskPoint `json:"disks,omitempty"`
+	// Messages received and sent since the store was created, kept across restarts
+	Received int64 `json:"recv"`
+	Sent     int64 `json:"sent"`
+}
+
+var lock sync.Mutex
+var startOnce sync.Once
+var started bool
+
+// Counters at the last restart, the bot's own counters start from 0
+var baseReceived, baseSent int64
+
+// Open buckets of the aggregated tiers, by tier name
+var buckets = map[string]*Point{}
+
+// Start records every sample of the background sampler, it can be called many times
+func Start() {
+	startOnce.Do(func() {
+		lock.Lock()
+		open()
+		started = true
+		lock.Unlock()
+		// The counters of the samples to come are the history's totals, counting across restarts
+		sysinfo.SetCounterSource(counters, func(info sysinfo.SysInfo) int64 {
+			received, sent := Totals(info)
+			return received + sent
+		})
+		sysinfo.StartSampler()
+		samples, _ := sysinfo.Subscribe()
+		go func() {
+			cleaned := time.Time{}
+			for s := range samples {
+				lock.Lock()
+				record(pointOf(s))
+				if time.Since(cleaned) > time.Hour {
+					cleanup(time.Now())
+					cleaned = time.Now()
+				}
+				lock.Unlock()
+			}
+		}()
+	})
+}
+
+// Enabled reports whether the store was started
+func Enabled() bool {
+	lock.Lock()
+	defer lock.Unlock()
+	return started
+}
+
+// open restores the counters and rebuilds the aggregated tiers from the raw samples they miss
+func open() {
+	now := time.Now()
+	raw := read(Tiers[0], now.Add(-Tiers[0].Retention), now)
+	// Counters only grow, so the highest ones are the latest
+	for _, tier := range Tiers {
+		if last, ok := lastPoint(tier, now); ok {
+			baseReceived, baseSent = max(baseReceived, last.Received), max(baseSent, last.Sent)
+		}
+	}
+	for _, tier := range Tiers[1:] {
+		from := time.Time{}
+		if last, ok := lastPoint(tier, now); ok {
+			from = last.At.Add(tier.Step)
+		}
+		for _, p := range raw {
+			if !p.At.Before(from) {
+				aggregate(tier, p)
+			}
+		}
+	}
+	slog.Info(fmt.Sprintf("Status: history opened with %d raw points", len(raw)))
+}
+
+func pointOf(s sysinfo.Sample) Point {
+	p := Point{
+		At:        s.At.UTC(),
+		N:         1,
+		CPU:       s.CpuUsedPercent,
+		CPUMin:    s.CpuUsedPercent,
+		CPUMax:    s.CpuUsedPercent,
+		Memory:    s.MemUsedPercent,
+		MemoryMin: s.MemUsedPercent,
+		MemoryMax: s.MemUsedPercent,
+		Load1:     s.CpuLoad1,
+		Load5:     s.CpuLoad5,
+		Load15:    s.CpuLoad15,
+		Disks:     map[string]DiskPoint{},
+		Received:  baseReceived + int64(s.ReceivedTotal),
+		Sent:      baseSent + int64(s.SentTotal),
+	}
+	for _, disk := range s.Disks {
+		p.Disks[disk.Name] = DiskPoint{Used: disk.Used, Percent: disk.UsedPercent}
+	}
+	return p
+}
+
+// record appends a sample to the raw tier and aggregates it into the others, the caller holds lock
+func record(p Point) {
+	if err := appendPoint(Tiers[0], p); err != nil {
+		slog.Error(fmt.Sprintf("Status: failed to record history: %v", err))
+	}
+	for _, tier := range Tiers[1:] {
+		aggregate(tier, p)
+	}
+}
+
+// aggregate merges a point into the open bucket of a tier, writing the bucket once a later one starts
+func aggregate(tier Tier, p Point) {
+	start := p.At.Truncate(tier.Step)
+	b := buckets[tier.Name]
+	if b != nil && !b.At.Equal(start) {
+		if err := appendPoint(tier, *b); err != nil {
+			slog.Error(fmt.Sprintf("Status: failed to record history: %v", err))
+		}
+		b = nil
+	}
+	if b == nil {
+		b = &Point{At: start}
+		buckets[tier.Name] = b
+	}
+	*b = merge(*b, p)
+	b.At = start
+}
+
+// merge aggregates two points, weighting the averages by their samples
+func merge(a Point, b Point) Point {
+	if a.N == 0 {
+		return b
+	}
+	if b.N == 0 {
+		return a
+	}
+	n := float64(a.N + b.N)
+	avg := func(x float64, y float64) float64 { return (x*float64(a.N) + y*float64(b.N)) / n }
+	r := Point{
+		At:        b.At,
+		N:         a.N + b.N,
+		CPU:       avg(a.CPU, b.CPU),
+		CPUMin:    min(a.CPUMin, b.CPUMin),
+		CPUMax:    max(a.CPUMax, b.CPUMax),
+		Memory:    avg(a.Memory, b.Memory),
+		MemoryMin: min(a.MemoryMin, b.MemoryMin),
+		MemoryMax: max(a.MemoryMax, b.MemoryMax),
+		Load1:     avg(a.Load1, b.Load1),
+		Load5:     avg(a.Load5, b.Load5),
+		Load15:    avg(a.Load15, b.Load15),
+		Disks:     map[string]DiskPoint{},
+		// Counters only grow, the latest wins
+		Received: max(a.Received, b.Received),
+		Sent:     max(a.Sent, b.Sent),
+	}
+	if b.At.Before(a.At) {
+		r.At = a.At
+	}
+	for name, disk := range a.Disks {
+		r.Disks[name] = disk
+	}
+	// The latest usage of a disk wins, like the counters
+	for name, disk := range b.Disks {
+		if _, ok := r.Disks[name]; !ok || !b.At.Before(a.At) {
+			r.Disks[name] = disk
+		}
+	}
+	return r
+}
+
+// Query returns the points between from and to, oldest first, at least step apart.
+// It reads the coarsest tier fine enough for step and still holding from
+func Query(from time.Time, to time.Time, step time.Duration) []Point {
+	lock.Lock()
+	defer lock.Unlock()
+	now := time.Now()
+	tier := Tiers[len(Tiers)-1]
+	for i := len(Tiers) - 1; i >= 0; i-- {
+		if Tiers[i].Step <= step && !from.Before(now.Add(-Tiers[i].Retention)) {
+			tier = Tiers[i]
+			break
+		}
+	}
+	points := read(tier, from, to)
+	// The open bucket is not written yet
+	if b := buckets[tier.Name]; b != nil && !b.At.Before(from) && !b.At.After(to) {
+		points = append(points, *b)
+	}
+	if step <= tier.Step {
+		return points
+	}
+	var result []Point
+	for _, p := range points {
+		start := p.At.Truncate(step)
+		if len(result) > 0 && result[len(result)-1].At.Truncate(step).Equal(start) {
+			result[len(result)-1] = merge(result[len(result)-1], p)
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// Totals returns the messages received and sent since the store was created
+func Totals(info sysinfo.SysInfo) (received int64, sent int64) {
+	lock.Lock()
+	defer lock.Unlock()
+	return baseReceived + int64(info.ReceivedTotal), baseSent + int64(info.SentTotal)
+}
+
+// counters returns the message counters between from and to, received and sent together
+func counters(from time.Time, to time.Time) []sysinfo.Counter {
+	var result []sysinfo.Counter
+	for _, p := range Query(from, to, time.Minute) {
+		result = append(result, sysinfo.Counter{At: p.At, Total: p.Received + p.Sent})
+	}
+	return result
+}
+
+// Summarize aggregates the points between from and to into a summary
+func Summarize(from time.Time, to time.Time) (s sysinfo.Summary) {
+	var first, last Point
+	for i, p := range Query(from, to, time.Minute) {
+		if i == 0 {
+			first = p
+			s.From = p.At
+		}
+		last = p
+		s.To = p.At
+		s.Samples += p.N
+		s.CPU.Merge(p.CPUMin, p.CPU, p.CPUMax, p.N)
+		s.Memory.Merge(p.MemoryMin, p.Memory, p.MemoryMax, p.N)
+	}
+	if s.Samples == 0 {
+		return
+	}
+	s.Received, s.Sent = int(last.Received-first.Received), int(last.Sent-first.Sent)
+	for name, disk := range last.Disks {
+		from := disk
+		if d, ok := first.Disks[name]; ok {
+			from = d
+		}
+		s.Disks = append(s.Disks, sysinfo.DiskGrowth{
+			Name:        name,
+			FromUsed:    from.Used,
+			ToUsed:      disk.Used,
+			FromPercent: from.Percent,
+			ToPercent:   disk.Percent,
+		})
+	}
+	sort.Slice(s.Disks, func(i, j int) bool { return s.Disks[i].Name < s.Disks[j].Name })
+	return
+}
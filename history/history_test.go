@@ -0,0 +1,124 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+// reset points the store to an empty directory
+func reset(t *testing.T) {
+	Dir = t.TempDir()
+	buckets = map[string]*Point{}
+	baseReceived, baseSent = 0, 0
+}
+
+func point(at time.Time, cpu float64, received int64) Point {
+	return Point{
+		At: at, N: 1,
+		CPU: cpu, CPUMin: cpu, CPUMax: cpu,
+		Disks:    map[string]DiskPoint{"/": {Used: uint64(received), Percent: cpu}},
+		Received: received,
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	reset(t)
+	base := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	for _, p := range []Point{
+		point(base, 10, 1),
+		point(base.Add(20*time.Second), 20, 2),
+		point(base.Add(40*time.Second), 30, 3),
+		point(base.Add(time.Minute), 50, 4),
+	} {
+		record(p)
+	}
+
+	minutes := read(Tiers[1], base, base.Add(time.Hour))
+	if len(minutes) != 1 {
+		t.Fatalf("got %d written 1m points, want 1 as the second minute is still open", len(minutes))
+	}
+	got := minutes[0]
+	if !got.At.Equal(base) || got.N != 3 || got.CPU != 20 || got.CPUMin != 10 || got.CPUMax != 30 || got.Received != 3 {
+		t.Errorf("1m point = %+v, want 3 samples at %v averaging 20 between 10 and 30", got, base)
+	}
+	if got.Disks["/"].Used != 3 {
+		t.Errorf("1m disk = %+v, want the latest usage", got.Disks["/"])
+	}
+	if b := buckets["1h"]; b == nil || b.N != 4 || b.CPU != 27.5 || !b.At.Equal(base) {
+		t.Errorf("open 1h bucket = %+v, want the 4 samples averaging 27.5", b)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	reset(t)
+	base := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	for i, cpu := range []float64{10, 20, 30, 40, 50, 60} {
+		record(point(base.Add(time.Duration(i)*30*time.Second), cpu, int64(i)))
+	}
+	to := base.Add(time.Hour)
+	tests := []struct {
+		name string
+		from time.Time
+		step time.Duration
+		want []float64
+	}{
+		{"raw", base, 0, []float64{10, 20, 30, 40, 50, 60}},
+		{"range", base.Add(time.Minute), 0, []float64{30, 40, 50, 60}},
+		{"minutes with the open bucket", base, time.Minute, []float64{15, 35, 55}},
+		{"merged steps", base, 2 * time.Minute, []float64{25, 55}},
+		{"hours", base, time.Hour, []float64{35}},
+		// Raw points are only kept for a day
+		{"out of the raw retention", base.Add(-48 * time.Hour), 0, []float64{35}},
+	}
+	for _, test := range tests {
+		points := Query(test.from, to, test.step)
+		var got []float64
+		for _, p := range points {
+			got = append(got, p.CPU)
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestOpenRebuildsTiers(t *testing.T) {
+	reset(t)
+	base := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	// The first minute was aggregated before the restart, the next two were lost with the open buckets
+	raw := []Point{
+		point(base, 10, 5),
+		point(base.Add(time.Minute), 20, 6),
+		point(base.Add(2*time.Minute), 30, 7),
+	}
+	for _, p := range raw {
+		if err := appendPoint(Tiers[0], p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := appendPoint(Tiers[1], point(base, 10, 5)); err != nil {
+		t.Fatal(err)
+	}
+
+	open()
+	if baseReceived != 7 {
+		t.Errorf("baseReceived = %d, want the latest counter 7", baseReceived)
+	}
+	minutes := read(Tiers[1], base, base.Add(time.Hour))
+	if len(minutes) != 2 || minutes[0].CPU != 10 || minutes[1].CPU != 20 {
+		t.Errorf("1m points = %+v, want the first minute once and the second rebuilt", minutes)
+	}
+	if b := buckets["1m"]; b == nil || b.CPU != 30 {
+		t.Errorf("open 1m bucket = %+v, want the third minute", b)
+	}
+	if b := buckets["1h"]; b == nil || b.N != 3 || b.CPU != 20 {
+		t.Errorf("open 1h bucket = %+v, want the 3 raw points", b)
+	}
+}
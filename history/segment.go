@@ -0,0 +1,108 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Segment names are the UTC start of the span they cover
+const segmentLayout = "20060102T1504"
+
+type segment struct {
+	path  string
+	start time.Time
+}
+
+func segmentPath(tier Tier, at time.Time) string {
+	return filepath.Join(Dir, tier.Name, at.UTC().Truncate(tier.SegmentSpan).Format(segmentLayout)+".jsonl")
+}
+
+// segments lists the segments of a tier, oldest first
+func segments(tier Tier) (result []segment) {
+	entries, _ := os.ReadDir(filepath.Join(Dir, tier.Name))
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".jsonl")
+		if !ok {
+			continue
+		}
+		start, err := time.Parse(segmentLayout, name)
+		if err != nil {
+			continue
+		}
+		result = append(result, segment{path: filepath.Join(Dir, tier.Name, e.Name()), start: start})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].start.Before(result[j].start) })
+	return
+}
+
+// appendPoint appends a point to the segment covering it
+func appendPoint(tier Tier, p Point) error {
+	path := segmentPath(tier, p.At)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, _ := json.Marshal(p)
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// read returns the points of a tier between from and to, oldest first
+func read(tier Tier, from time.Time, to time.Time) (points []Point) {
+	for _, seg := range segments(tier) {
+		if !seg.start.Before(to) || !seg.start.Add(tier.SegmentSpan).After(from) {
+			continue
+		}
+		f, err := os.Open(seg.path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var p Point
+			// A line cut by a crash is skipped
+			if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+				continue
+			}
+			if !p.At.Before(from) && !p.At.After(to) {
+				points = append(points, p)
+			}
+		}
+		f.Close()
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].At.Before(points[j].At) })
+	return
+}
+
+// lastPoint returns the latest point of a tier
+func lastPoint(tier Tier, now time.Time) (Point, bool) {
+	segs := segments(tier)
+	for i := len(segs) - 1; i >= 0; i-- {
+		points := read(tier, segs[i].start, now.Add(time.Hour))
+		if len(points) > 0 {
+			return points[len(points)-1], true
+		}
+	}
+	return Point{}, false
+}
+
+// cleanup deletes the segments out of the retention of their tier
+func cleanup(now time.Time) {
+	for _, tier := range Tiers {
+		for _, seg := range segments(tier) {
+			if seg.start.Add(tier.SegmentSpan).Before(now.Add(-tier.Retention)) {
+				os.Remove(seg.path)
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package i18n
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is used when a locale is empty or has no catalog
+var DefaultLocale = "en"
+
+// Message is a translated string with its plural forms, a format string for fmt.Sprintf
+type Message struct {
+	One   string
+	Other string
+}
+
+type catalog struct {
+	// Plural rule, reports whether n takes the "one" form
+	one func(n int64) bool
+	// Number formatting
+	decimal string
+	group   string
+	// Messages by key
+	messages map[string]Message
+}
+
+var catalogs = map[string]*catalog{}
+var catalogsLock sync.RWMutex
+
+func init() {
+	catalogs["en"] = &catalog{
+		one:      func(n int64) bool { return n == 1 },
+		decimal:  ".",
+		group:    ",",
+		messages: en,
+	}
+	catalogs["zh-CN"] = &catalog{
+		one:      func(n int64) bool { return false },
+		decimal:  ".",
+		group:    ",",
+		messages: zhCN,
+	}
+}
+
+// Register adds or overrides a message of a locale, creating the locale with English rules if needed
+func Register(locale string, key string, msg Message) {
+	catalogsLock.Lock()
+	defer catalogsLock.Unlock()
+	c, ok := catalogs[locale]
+	if !ok {
+		c = &catalog{
+			one:      catalogs["en"].one,
+			decimal:  ".",
+			group:    ",",
+			messages: map[string]Message{},
+		}
+		catalogs[locale] = c
+	}
+	c.messages[key] = msg
+}
+
+// Locales returns all locales having a catalog
+func Locales() (result []string) {
+	catalogsLock.RLock()
+	defer catalogsLock.RUnlock()
+	for locale := range catalogs {
+		result = append(result, locale)
+	}
+	return
+}
+
+// Match returns the best locale having a catalog, "zh_cn" and "zh" both match "zh-CN"
+func Match(locale string) string {
+	catalogsLock.RLock()
+	defer catalogsLock.RUnlock()
+	locale = strings.ReplaceAll(locale, "_", "-")
+	for name := range catalogs {
+		if strings.EqualFold(name, locale) {
+			return name
+		}
+	}
+	// Sorted, so the same locale is picked every time when several share the language
+	names := make([]string, 0, len(catalogs))
+	for name := range catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lang, _, _ := strings.Cut(locale, "-")
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(name, "-")
+		if lang != "" && strings.EqualFold(prefix, lang) {
+			return name
+		}
+	}
+	return DefaultLocale
+}
+
+func lookup(locale string) *catalog {
+	locale = Match(locale)
+	catalogsLock.RLock()
+	defer catalogsLock.RUnlock()
+	if c, ok := catalogs[locale]; ok {
+		return c
+	}
+	return catalogs["en"]
+}
+
+func (c *catalog) message(key string) (Message, bool) {
+	catalogsLock.RLock()
+	defer catalogsLock.RUnlock()
+	if msg, ok := c.messages[key]; ok {
+		return msg, true
+	}
+	msg, ok := catalogs["en"].messages[key]
+	return msg, ok
+}
+
+// T translates key and formats it with args
+func T(locale string, key string, args ...any) string {
+	msg, ok := lookup(locale).message(key)
+	if !ok {
+		return key
+	}
+	return fmt.Sprintf(msg.Other, args...)
+}
+
+// N translates key using the plural form for n, then formats it with args
+func N(locale string, key string, n int64, args ...any) string {
+	c := lookup(locale)
+	msg, ok := c.message(key)
+	if !ok {
+		return key
+	}
+	if c.one(n) && msg.One != "" {
+		return fmt.Sprintf(msg.One, args...)
+	}
+	return fmt.Sprintf(msg.Other, args...)
+}
+
+// Number formats v with prec decimals and the locale's separators, e.g. 1,234.56
+func Number(locale string, v float64, prec int) string {
+	c := lookup(locale)
+	str := fmt.Sprintf("%.*f", prec, math.Abs(v))
+	intPart, fracPart, _ := strings.Cut(str, ".")
+
+	var builder strings.Builder
+	if v < 0 && strings.Trim(str, "0.") != "" {
+		builder.WriteString("-")
+	}
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			builder.WriteString(c.group)
+		}
+		builder.WriteRune(digit)
+	}
+	if fracPart != "" {
+		builder.WriteString(c.decimal)
+		builder.WriteString(fracPart)
+	}
+	return builder.String()
+}
@@ -0,0 +1,161 @@
+package i18n
+
+var en = map[string]Message{
+	"title":                {Other: "Gonebot on %s"},
+	"recv":                 {Other: "Recv: %s"},
+	"sent":                 {Other: "Sent: %s"},
+	"uptime.sys":           {Other: "Sys: %s"},
+	"uptime.bot":           {Other: "Bot: %s"},
+	"cpu":                  {One: "CPU %s | Core: %d", Other: "CPU %s | Cores: %d"},
+	"cpu.load":             {Other: "Load: %s / %s / %s"},
+	"memory":               {Other: "Memory | Total: %s"},
+	"disk":                 {Other: "Disk: \"%s\""},
+	"disk.unnamed":         {Other: "Disk"},
+	"usage":                {Other: "%s / %s"},
+	"percent":              {Other: "%s%%"},
+	"duration.days":        {Other: "%dd"},
+	"duration.hours":       {Other: "%dh"},
+	"duration.minutes":     {Other: "%dm"},
+	"duration.seconds":     {Other: "%ds"},
+	"duration.separator":   {Other: " "},
+	"cache.age":            {Other: "as of %s ago"},
+	"mini.memory":          {Other: "Mem"},
+	"mini.disk":            {Other: "Disk"},
+	"alert.firing":         {Other: "[%s] %s is firing: %s"},
+	"alert.repeat":         {Other: "[%s] %s is still firing after %s: %s"},
+	"alert.resolved":       {Other: "[resolved] %s resolved after %s: %s"},
+	"alert.cpu":            {Other: "CPU"},
+	"alert.memory":         {Other: "Memory"},
+	"alert.disk":           {Other: "Disk \"%s\""},
+	"alert.load1":          {Other: "Load (1m)"},
+	"alert.load5":          {Other: "Load (5m)"},
+	"alert.load15":         {Other: "Load (15m)"},
+	"muted":                {Other: "Muted: %s"},
+	"alert.denied":         {Other: "Only admins can manage alerts"},
+	"alert.none":           {Other: "No alerts"},
+	"alert.list":           {Other: "Alerts:"},
+	"alert.item":           {Other: "#%d [%s] %s %s for %s: %s"},
+	"alert.acked":          {Other: " (acked by %s)"},
+	"alert.silenced":       {Other: " (silenced)"},
+	"alert.state.pending":  {Other: "pending"},
+	"alert.state.firing":   {Other: "firing"},
+	"alert.silences":       {Other: "Silences:"},
+	"alert.silence.item":   {Other: "%s for %s"},
+	"alert.silence.usage":  {Other: "Usage: %s silence <rule> <duration>, e.g. 2h or 1d, 0 lifts it"},
+	"alert.silence.set":    {Other: "Silenced %s for %s"},
+	"alert.silence.lifted": {Other: "Lifted the silence of %s"},
+	"alert.rule.unknown":   {Other: "Unknown rule %s, rules: %s"},
+	"alert.ack.usage":      {Other: "Usage: %s ack <id>"},
+	"alert.ack.done":       {Other: "Acknowledged alert #%d"},
+	"alert.ack.unknown":    {Other: "No alert #%d"},
+	"report.summary":       {Other: "Summary of the last %s"},
+	"report.empty":         {Other: "No samples yet"},
+	"report.cpu":           {Other: "CPU: min %s, avg %s, max %s"},
+	"report.memory":        {Other: "Memory: min %s, avg %s, max %s"},
+	"report.disk":          {Other: "Disk \"%s\": %s → %s (%s)"},
+	"report.messages":      {Other: "Messages: %s received, %s sent"},
+	"lifetime.recv":        {Other: "Σ Recv: %s"},
+	"lifetime.sent":        {Other: "Σ Sent: %s"},
+	"lifetime.uptime":      {Other: "Σ Bot: %s"},
+	"lifetime.restarts":    {One: "%d restart", Other: "%d restarts"},
+	"lifetime.last":        {Other: "Restarted %s ago, %s"},
+	"restart.first":        {Other: "first start"},
+	"restart.clean":        {Other: "clean"},
+	"restart.crash":        {Other: "crash"},
+	"activity":             {Other: "Bot activity"},
+	"activity.rate":        {Other: "%s msg/min"},
+	"activity.peak":        {Other: "Peak (1h): %s msg/min"},
+	"activity.today":       {Other: "Today: %s"},
+	"activity.week":        {Other: "This week: %s"},
+	"leaderboard.groups":   {Other: "Top groups"},
+	"leaderboard.users":    {Other: "Top users"},
+	"leaderboard.plugins":  {Other: "Top plugins"},
+	"leaderboard.item":     {Other: "#%d %s: %s"},
+	"leaderboard.empty":    {Other: "No activity counted yet"},
+	"leaderboard.off":      {Other: "Activity is not counted"},
+	"leaderboard.optout":   {Other: "You are no longer counted on the activity board"},
+	"leaderboard.optin":    {Other: "You are counted on the activity board again"},
+	"latency.ms":           {Other: "%sms"},
+	"latency.s":            {Other: "%ss"},
+	"render.time":          {Other: "rendered in %s"},
+}
+
+var zhCN = map[string]Message{
+	"title":                {Other: "Gonebot 运行于 %s"},
+	"recv":                 {Other: "收到: %s"},
+	"sent":                 {Other: "发送: %s"},
+	"uptime.sys":           {Other: "系统: %s"},
+	"uptime.bot":           {Other: "Bot: %s"},
+	"cpu":                  {Other: "CPU %s | 核心数: %d"},
+	"cpu.load":             {Other: "负载: %s / %s / %s"},
+	"memory":               {Other: "内存 | 总量: %s"},
+	"disk":                 {Other: "磁盘: \"%s\""},
+	"disk.unnamed":         {Other: "磁盘"},
+	"usage":                {Other: "%s / %s"},
+	"percent":              {Other: "%s%%"},
+	"duration.days":        {Other: "%d天"},
+	"duration.hours":       {Other: "%d小时"},
+	"duration.minutes":     {Other: "%d分"},
+	"duration.seconds":     {Other: "%d秒"},
+	"duration.separator":   {Other: ""},
+	"cache.age":            {Other: "%s前的数据"},
+	"mini.memory":          {Other: "内存"},
+	"mini.disk":            {Other: "磁盘"},
+	"alert.firing":         {Other: "[%s] %s 告警: %s"},
+	"alert.repeat":         {Other: "[%s] %s 已持续告警 %s: %s"},
+	"alert.resolved":       {Other: "[已恢复] %s 在 %s 后恢复: %s"},
+	"alert.cpu":            {Other: "CPU"},
+	"alert.memory":         {Other: "内存"},
+	"alert.disk":           {Other: "磁盘 \"%s\""},
+	"alert.load1":          {Other: "负载 (1分钟)"},
+	"alert.load5":          {Other: "负载 (5分钟)"},
+	"alert.load15":         {Other: "负载 (15分钟)"},
+	"muted":                {Other: "已静音: %s"},
+	"alert.denied":         {Other: "只有管理员可以管理告警"},
+	"alert.none":           {Other: "没有告警"},
+	"alert.list":           {Other: "告警:"},
+	"alert.item":           {Other: "#%d [%s] %s %s 持续 %s: %s"},
+	"alert.acked":          {Other: " (%s 已确认)"},
+	"alert.silenced":       {Other: " (已静音)"},
+	"alert.state.pending":  {Other: "待定"},
+	"alert.state.firing":   {Other: "告警中"},
+	"alert.silences":       {Other: "静音:"},
+	"alert.silence.item":   {Other: "%s 剩余 %s"},
+	"alert.silence.usage":  {Other: "用法: %s silence <规则> <时长>, 例如 2h 或 1d, 0 取消静音"},
+	"alert.silence.set":    {Other: "已静音 %s %s"},
+	"alert.silence.lifted": {Other: "已取消 %s 的静音"},
+	"alert.rule.unknown":   {Other: "未知规则 %s, 规则: %s"},
+	"alert.ack.usage":      {Other: "用法: %s ack <编号>"},
+	"alert.ack.done":       {Other: "已确认告警 #%d"},
+	"alert.ack.unknown":    {Other: "没有告警 #%d"},
+	"report.summary":       {Other: "最近 %s 的汇总"},
+	"report.empty":         {Other: "暂无数据"},
+	"report.cpu":           {Other: "CPU: 最低 %s, 平均 %s, 最高 %s"},
+	"report.memory":        {Other: "内存: 最低 %s, 平均 %s, 最高 %s"},
+	"report.disk":          {Other: "磁盘 \"%s\": %s → %s (%s)"},
+	"report.messages":      {Other: "消息: 收到 %s, 发送 %s"},
+	"lifetime.recv":        {Other: "总收到: %s"},
+	"lifetime.sent":        {Other: "总发送: %s"},
+	"lifetime.uptime":      {Other: "总运行: %s"},
+	"lifetime.restarts":    {Other: "重启 %d 次"},
+	"lifetime.last":        {Other: "%s前重启, %s"},
+	"restart.first":        {Other: "首次启动"},
+	"restart.clean":        {Other: "正常"},
+	"restart.crash":        {Other: "崩溃"},
+	"activity":             {Other: "机器人活动"},
+	"activity.rate":        {Other: "%s 条/分"},
+	"activity.peak":        {Other: "峰值 (1h): %s 条/分"},
+	"activity.today":       {Other: "今日: %s"},
+	"activity.week":        {Other: "本周: %s"},
+	"leaderboard.groups":   {Other: "活跃群组"},
+	"leaderboard.users":    {Other: "活跃用户"},
+	"leaderboard.plugins":  {Other: "热门插件"},
+	"leaderboard.item":     {Other: "#%d %s: %s"},
+	"leaderboard.empty":    {Other: "暂无活动统计"},
+	"leaderboard.off":      {Other: "未开启活动统计"},
+	"leaderboard.optout":   {Other: "已停止统计你的消息"},
+	"leaderboard.optin":    {Other: "已恢复统计你的消息"},
+	"latency.ms":           {Other: "%s毫秒"},
+	"latency.s":            {Other: "%s秒"},
+	"render.time":          {Other: "渲染耗时 %s"},
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/server"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+	"github.com/gonebot-dev/goneplugin-status/timing"
+)
+
+// Path the metrics are served at
+var Path = "/metrics"
+
+// Register adds the metrics endpoint to the built-in server and starts the sampler.
+// Snapshots are redacted with redaction, masked mount paths become labels like "disk1"
+func Register(redaction sysinfo.Redaction) {
+	sysinfo.StartSampler()
+	server.Handle(Path, server.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, at := sysinfo.Latest()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Write(w, info.Redact(redaction), at)
+		WriteTimings(w, timing.Snapshot())
+	})))
+}
+
+// Write writes a snapshot in the Prometheus text exposition format.
+// Disks without a name, e.g. with their mount paths omitted by a Redaction, are left out
+func Write(w io.Writer, info sysinfo.SysInfo, at time.Time) {
+	var disks []sysinfo.DiskInfo
+	for _, disk := range info.Disks {
+		if disk.Name != "" {
+			disks = append(disks, disk)
+		}
+	}
+	e := exposition{w: w}
+	// Host
+	e.metric("status_cpu_usage_percent", "gauge", "CPU usage in percent.")
+	e.sample("status_cpu_usage_percent", nil, info.CpuUsedPercent)
+	e.metric("status_cpu_cores", "gauge", "Number of logical CPU cores.")
+	e.sample("status_cpu_cores", nil, float64(info.CpuCores))
+	e.metric("status_load_average", "gauge", "System load average.")
+	e.sample("status_load_average", []string{"period", "1m"}, info.CpuLoad1)
+	e.sample("status_load_average", []string{"period", "5m"}, info.CpuLoad5)
+	e.sample("status_load_average", []string{"period", "15m"}, info.CpuLoad15)
+	e.metric("status_memory_total_bytes", "gauge", "Total memory in bytes.")
+	e.sample("status_memory_total_bytes", nil, float64(info.MemAll))
+	e.metric("status_memory_used_bytes", "gauge", "Used memory in bytes.")
+	e.sample("status_memory_used_bytes", nil, float64(info.MemUsed))
+	e.metric("status_disk_total_bytes", "gauge", "Total size of a partition in bytes.")
+	for _, disk := range disks {
+		e.sample("status_disk_total_bytes", []string{"mountpoint", disk.Name}, float64(disk.Total))
+	}
+	e.metric("status_disk_used_bytes", "gauge", "Used size of a partition in bytes.")
+	for _, disk := range disks {
+		e.sample("status_disk_used_bytes", []string{"mountpoint", disk.Name}, float64(disk.Used))
+	}
+	e.metric("status_host_uptime_seconds", "gauge", "Time since the host booted.")
+	e.sample("status_host_uptime_seconds", nil, float64(info.Uptime))
+	e.metric("status_sample_timestamp_seconds", "gauge", "When the snapshot was sampled.")
+	e.sample("status_sample_timestamp_seconds", nil, float64(at.UnixMilli())/1000.0)
+
+	// Gonebot
+	e.metric("gonebot_info", "gauge", "Gonebot information, always 1.")
+	e.sample("gonebot_info", []string{"adapter", info.Backend, "os", info.OS, "arch", info.Arch, "platform", info.Platform}, 1)
+	e.metric("gonebot_messages_received_total", "counter", "Messages received since the bot started.")
+	e.sample("gonebot_messages_received_total", []string{"adapter", info.Backend}, float64(info.ReceivedTotal))
+	e.metric("gonebot_messages_sent_total", "counter", "Messages sent since the bot started.")
+	e.sample("gonebot_messages_sent_total", []string{"adapter", info.Backend}, float64(info.SentTotal))
+	e.metric("gonebot_uptime_seconds", "gauge", "Time since the bot started.")
+	e.sample("gonebot_uptime_seconds", []string{"adapter", info.Backend}, float64(info.BotUptime))
+	if l := info.Lifetime; l != nil {
+		e.metric("gonebot_lifetime_messages_received_total", "counter", "Messages received across restarts.")
+		e.sample("gonebot_lifetime_messages_received_total", []string{"adapter", info.Backend}, float64(l.Received))
+		e.metric("gonebot_lifetime_messages_sent_total", "counter", "Messages sent across restarts.")
+		e.sample("gonebot_lifetime_messages_sent_total", []string{"adapter", info.Backend}, float64(l.Sent))
+		e.metric("gonebot_lifetime_uptime_seconds_total", "counter", "Uptime of the bot across restarts.")
+		e.sample("gonebot_lifetime_uptime_seconds_total", []string{"adapter", info.Backend}, float64(l.Uptime))
+		e.metric("gonebot_restarts_total", "counter", "Restarts of the bot.")
+		e.sample("gonebot_restarts_total", []string{"adapter", info.Backend}, float64(l.Restarts))
+		e.metric("gonebot_last_restart_timestamp_seconds", "gauge", "When the bot last started, by whether the previous run stopped cleanly.")
+		e.sample("gonebot_last_restart_timestamp_seconds", []string{"adapter", info.Backend, "reason", l.LastRestartReason}, float64(l.LastRestart.Unix()))
+	}
+	if len(info.Plugins) > 0 {
+		e.metric("gonebot_plugin_info", "gauge", "Plugin information, always 1.")
+		for _, p := range info.Plugins {
+			e.sample("gonebot_plugin_info", []string{"plugin", p.Name, "version", p.Version}, 1)
+		}
+		e.metric("gonebot_plugin_invocations_total", "counter", "Handler calls of a plugin.")
+		for _, p := range info.Plugins {
+			e.sample("gonebot_plugin_invocations_total", []string{"plugin", p.Name}, float64(p.Invocations))
+		}
+		e.metric("gonebot_plugin_errors_total", "counter", "Handler calls of a plugin that panicked.")
+		for _, p := range info.Plugins {
+			e.sample("gonebot_plugin_errors_total", []string{"plugin", p.Name}, float64(p.Errors))
+		}
+		e.metric("gonebot_plugin_latency_seconds_total", "counter", "Run time of the handler calls of a plugin.")
+		for _, p := range info.Plugins {
+			e.sample("gonebot_plugin_latency_seconds_total", []string{"plugin", p.Name}, p.Latency.Seconds())
+		}
+	}
+}
+
+// WriteTimings writes the durations of the render phases and the sampler as a Prometheus summary
+func WriteTimings(w io.Writer, stats map[string]timing.Stats) {
+	if len(stats) == 0 {
+		return
+	}
+	e := exposition{w: w}
+	phases := make([]string, 0, len(stats))
+	for phase := range stats {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+	e.metric("status_render_duration_seconds", "summary", "Time spent in a phase of rendering the status card, or sampling in the background for phase \"sample\".")
+	for _, phase := range phases {
+		s := stats[phase]
+		e.sample("status_render_duration_seconds", []string{"phase", phase, "quantile", "0.5"}, s.P50.Seconds())
+		e.sample("status_render_duration_seconds", []string{"phase", phase, "quantile", "0.95"}, s.P95.Seconds())
+		e.sample("status_render_duration_seconds", []string{"phase", phase, "quantile", "0.99"}, s.P99.Seconds())
+		e.sample("status_render_duration_seconds_sum", []string{"phase", phase}, s.Sum.Seconds())
+		e.sample("status_render_duration_seconds_count", []string{"phase", phase}, float64(s.Count))
+	}
+}
+
+type exposition struct {
+	w io.Writer
+}
+
+func (e exposition) metric(name string, kind string, help string) {
+	fmt.Fprintf(e.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes a sample, labels being name and value pairs
+func (e exposition) sample(name string, labels []string, value float64) {
+	var pairs []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1])))
+	}
+	if len(pairs) > 0 {
+		name += "{" + strings.Join(pairs, ",") + "}"
+	}
+	fmt.Fprintf(e.w, "%s %g\n", name, value)
+}
+
+// Label values escape backslashes, double quotes and line feeds
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,56 @@
+package status
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gonebot-dev/gonebot/message"
+	"github.com/gonebot-dev/gonebot/plugin"
+	"github.com/gonebot-dev/gonebot/plugin/handler"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+)
+
+// Show the plugins wrapped with Track and this one on the card, with their handler calls, errors and latency
+var EnablePlugins = false
+
+// Track counts the messages matched by a plugin's handlers on the activity board, and their calls, panics and
+// run time on the plugins panel. A panicking handler is recovered and lets the message through. Load the plugin it returns:
+//
+//	gonebot.LoadPlugin(status.Track(echo.Echo))
+func Track(p plugin.GonePlugin) plugin.GonePlugin {
+	sysinfo.RegisterPlugin(p.Name, p.Version, p.Description)
+	handlers := make([]handler.GoneHandler, len(p.Handlers))
+	for i, h := range p.Handlers {
+		h.Matcher = trackMatcher(p.Name, h.Matcher)
+		h.Handler = trackHandler(p.Name, h.Handler)
+		handlers[i] = h
+	}
+	p.Handlers = handlers
+	return p
+}
+
+func trackMatcher(name string, matcher func(msg message.Message) bool) func(msg message.Message) bool {
+	return func(msg message.Message) bool {
+		if !matcher(msg) {
+			return false
+		}
+		sysinfo.RecordMatch(name)
+		return true
+	}
+}
+
+func trackHandler(name string, h func(incomingMsg message.Message, resultMsg *message.Message) bool) func(incomingMsg message.Message, resultMsg *message.Message) bool {
+	return func(incomingMsg message.Message, resultMsg *message.Message) (handled bool) {
+		start := time.Now()
+		defer func() {
+			err := recover()
+			if err != nil {
+				slog.Error(fmt.Sprintf("Status: a handler of %s panicked: %v", name, err))
+				handled = false
+			}
+			sysinfo.RecordInvocation(name, time.Since(start), err != nil)
+		}()
+		return h(incomingMsg, resultMsg)
+	}
+}
@@ -0,0 +1,100 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fogleman/gg"
+	"github.com/gonebot-dev/goneplugin-status/format"
+	"github.com/gonebot-dev/goneplugin-status/i18n"
+)
+
+type cacheEntry struct {
+	img image.Image
+	at  time.Time
+	// Closed once img is ready, so concurrent callers wait for the same render
+	done chan struct{}
+	// False if the render panicked
+	ok bool
+}
+
+var cache = map[string]*cacheEntry{}
+var cacheLock sync.Mutex
+
+// CacheKey identifies the renders that look the same
+func (opts Options) CacheKey() string {
+	scale, width := opts.size()
+	return fmt.Sprintf("%s|%s|%s|%g|%g|%d|%s", opts.View, opts.Section, i18n.Match(opts.Locale), scale, width, opts.Columns, strings.Join(opts.Muted, ","))
+}
+
+// Cached returns the image cached for key if younger than ttl, otherwise it renders a new one.
+// Concurrent calls for the same key share a single render.
+func Cached(key string, ttl time.Duration, render func() image.Image) (image.Image, time.Time) {
+	cacheLock.Lock()
+	now := time.Now()
+	for k, entry := range cache {
+		if isReady(entry) && now.Sub(entry.at) >= ttl {
+			delete(cache, k)
+		}
+	}
+	entry, ok := cache[key]
+	if !ok {
+		entry = &cacheEntry{done: make(chan struct{})}
+		cache[key] = entry
+	}
+	cacheLock.Unlock()
+
+	if ok {
+		<-entry.done
+		if !entry.ok {
+			// The render panicked in another call, render again
+			return Cached(key, ttl, render)
+		}
+		return entry.img, entry.at
+	}
+	defer func() {
+		// A panicking render is not cached, the panic goes on once the waiters are released
+		if !entry.ok {
+			cacheLock.Lock()
+			if cache[key] == entry {
+				delete(cache, key)
+			}
+			cacheLock.Unlock()
+		}
+		close(entry.done)
+	}()
+	entry.img = render()
+	entry.at = time.Now()
+	entry.ok = true
+	return entry.img, entry.at
+}
+
+func isReady(entry *cacheEntry) bool {
+	select {
+	case <-entry.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// DrawAge returns a copy of img with an "as of 3s ago" note in the bottom right corner
+func DrawAge(img image.Image, age time.Duration, opts Options) image.Image {
+	scale, _ := opts.size()
+	locale := opts.Locale
+	dc := gg.NewContextForImage(img)
+	face := newFace(24 * scale)
+	defer face.Close()
+	dc.SetFontFace(face)
+	dc.SetHexColor("#FFFFFF")
+	dc.DrawStringAnchored(
+		i18n.T(locale, "cache.age", format.Duration(locale, age)),
+		float64(dc.Width())-panelMargin*scale,
+		float64(dc.Height())-panelMargin*scale/2.0,
+		1, 0.5,
+	)
+	return dc.Image()
+}
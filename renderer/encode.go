@@ -0,0 +1,198 @@
+package renderer
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/gonebot-dev/goneplugin-status/timing"
+	"github.com/nfnt/resize"
+)
+
+// Built-in formats
+const (
+	PNG        = "png"
+	JPEG       = "jpeg"
+	PalettePNG = "png8"
+)
+
+// Encoder writes img to w, quality is in 1..100 and may be ignored by lossless formats
+type Encoder func(w io.Writer, img image.Image, enc Encoding, quality int) error
+
+var encoders = map[string]Encoder{
+	PNG: func(w io.Writer, img image.Image, enc Encoding, quality int) error {
+		encoder := png.Encoder{CompressionLevel: enc.PNGCompression}
+		return encoder.Encode(w, img)
+	},
+	JPEG: func(w io.Writer, img image.Image, enc Encoding, quality int) error {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	},
+	PalettePNG: func(w io.Writer, img image.Image, enc Encoding, quality int) error {
+		paletted, ok := img.(*image.Paletted)
+		if !ok {
+			paletted = dither(img, adaptivePalette(img, 256))
+		}
+		encoder := png.Encoder{CompressionLevel: enc.PNGCompression}
+		return encoder.Encode(w, paletted)
+	},
+}
+var encodersLock sync.RWMutex
+
+// RegisterEncoder adds an output format, e.g. "webp" backed by an external encoder
+func RegisterEncoder(format string, encoder Encoder) {
+	encodersLock.Lock()
+	defer encodersLock.Unlock()
+	encoders[format] = encoder
+}
+
+// Encoding controls how the image is encoded
+type Encoding struct {
+	// Format name, PNG when empty
+	Format string
+	// Compression level of PNG and PalettePNG
+	PNGCompression png.CompressionLevel
+	// Quality of lossy formats, 90 when 0
+	Quality int
+	// Maximum size in bytes, 0 for no limit.
+	// Lossy quality is lowered first and PNG falls back to PalettePNG, then the image is scaled down until it fits.
+	// Encoded.OverBudget is set if it does not fit at the lowest quality and scale
+	MaxBytes int
+}
+
+// Encoded is an encoded image
+type Encoded struct {
+	Data   []byte
+	Format string
+	// Final quality and scale after fitting the size budget
+	Quality int
+	Scale   float64
+	Width   int
+	Height  int
+	// The image exceeds Encoding.MaxBytes even at the lowest quality and scale
+	OverBudget bool
+}
+
+// Size in bytes
+func (e Encoded) Size() int {
+	return len(e.Data)
+}
+
+// Lossy quality goes down to this value before scaling
+const minQuality = 40
+
+// Precision of the quality search, finer steps barely change the size but cost an encode each
+const qualityStep = 5
+
+// Scale goes down to this value, the result is flagged OverBudget if it still exceeds the budget
+const minScale = 0.25
+
+// Encode encodes img, lowering quality and scale to fit enc.MaxBytes.
+// The result is flagged OverBudget if it does not fit at minQuality and minScale
+func Encode(img image.Image, enc Encoding) (result Encoded, err error) {
+	defer timing.Start(timing.Encode)()
+	if enc.Format == "" {
+		enc.Format = PNG
+	}
+	if enc.Quality <= 0 || enc.Quality > 100 {
+		enc.Quality = 90
+	}
+	encoder, err := encoderOf(enc.Format)
+	if err != nil {
+		return result, err
+	}
+
+	// The palette is picked once from the full image, the scaled ones are only dithered to it
+	var palette color.Palette
+	if enc.Format == PalettePNG {
+		palette = adaptivePalette(img, 256)
+	}
+	scale := 1.0
+	for {
+		scaled := img
+		if scale < 1 {
+			scaled = resize.Resize(uint(float64(img.Bounds().Dx())*scale), 0, img, resize.Lanczos3)
+		}
+		if palette != nil {
+			scaled = dither(scaled, palette)
+		}
+		if result, err = fit(encoder, scaled, enc, scale); err != nil {
+			return
+		}
+		if enc.MaxBytes <= 0 || result.Size() <= enc.MaxBytes {
+			return
+		}
+		// 256 colors usually fit before losing pixels
+		if enc.Format == PNG {
+			enc.Format = PalettePNG
+			if encoder, err = encoderOf(PalettePNG); err != nil {
+				return
+			}
+			palette = adaptivePalette(img, 256)
+			continue
+		}
+		if scale <= minScale {
+			result.OverBudget = true
+			return
+		}
+		// The size follows the area, aim a little under the budget
+		estimate := scale * math.Sqrt(float64(enc.MaxBytes)/float64(result.Size())) * 0.9
+		scale = math.Max(math.Min(estimate, scale*0.9), minScale)
+	}
+}
+
+func encoderOf(format string) (Encoder, error) {
+	encodersLock.RLock()
+	defer encodersLock.RUnlock()
+	encoder, ok := encoders[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown image format %q", format)
+	}
+	return encoder, nil
+}
+
+// fit encodes img at enc.Quality, or for JPEG at the highest quality down to minQuality that fits enc.MaxBytes.
+// If none fits, the minQuality encode is returned
+func fit(encoder Encoder, img image.Image, enc Encoding, scale float64) (Encoded, error) {
+	encode := func(quality int) (Encoded, error) {
+		var buf bytes.Buffer
+		if err := encoder(&buf, img, enc, quality); err != nil {
+			return Encoded{}, err
+		}
+		return Encoded{
+			Data:    buf.Bytes(),
+			Format:  enc.Format,
+			Quality: quality,
+			Scale:   scale,
+			Width:   img.Bounds().Dx(),
+			Height:  img.Bounds().Dy(),
+		}, nil
+	}
+	best, err := encode(enc.Quality)
+	if err != nil || enc.MaxBytes <= 0 || best.Size() <= enc.MaxBytes || enc.Format != JPEG || enc.Quality <= minQuality {
+		return best, err
+	}
+	if best, err = encode(minQuality); err != nil || best.Size() > enc.MaxBytes {
+		return best, err
+	}
+	// minQuality fits and enc.Quality does not, search in between by steps of qualityStep
+	lo, hi := 1, (enc.Quality-1-minQuality)/qualityStep
+	for lo <= hi {
+		step := (lo + hi) / 2
+		e, err := encode(minQuality + step*qualityStep)
+		if err != nil {
+			return e, err
+		}
+		if e.Size() <= enc.MaxBytes {
+			best, lo = e, step+1
+		} else {
+			hi = step - 1
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,62 @@
+package renderer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+)
+
+// card renders the full view of a made-up snapshot
+func card() sysinfo.SysInfo {
+	return sysinfo.SysInfo{
+		Disks: []sysinfo.DiskInfo{
+			{Name: "/", Total: 512 << 30, Used: 300 << 30, UsedPercent: 58.6},
+			{Name: "/data", Total: 4 << 40, Used: 3 << 40, UsedPercent: 75},
+		},
+		MemAll: 32 << 30, MemUsed: 20 << 30, MemUsedPercent: 62.5,
+		Uptime: 86400 * 3, BotUptime: 3600 * 5,
+		CpuUsedPercent: 37.5, CpuCores: 8, CpuInfo: "Intel(R) Core(TM) i7-9700 CPU @ 3.00GHz",
+		CpuLoad1: 1.2, CpuLoad5: 0.8, CpuLoad15: 0.5,
+		OS: "linux", Arch: "amd64", Hostname: "bot", Platform: "debian", PlatformVersion: "12",
+		SentTotal: 1234, ReceivedTotal: 5678, Backend: "onebotv11",
+	}
+}
+
+func TestEncodeBudget(t *testing.T) {
+	img := RenderImage(card(), Options{})
+	tests := []struct {
+		name string
+		enc  Encoding
+		// Whether the budget must be met or must be reported as missed, either may happen when false
+		fits, misses bool
+	}{
+		{"no limit", Encoding{Format: PNG}, true, false},
+		{"jpeg quality", Encoding{Format: JPEG, MaxBytes: 200000}, true, false},
+		{"jpeg scale", Encoding{Format: JPEG, MaxBytes: 60000}, true, false},
+		{"png", Encoding{Format: PNG, MaxBytes: 300000}, true, false},
+		{"palette png", Encoding{Format: PalettePNG, MaxBytes: 150000}, true, false},
+		{"tight jpeg", Encoding{Format: JPEG, MaxBytes: 20000}, false, false},
+		{"tight png", Encoding{Format: PNG, MaxBytes: 50000}, false, false},
+		{"impossible", Encoding{Format: JPEG, MaxBytes: 500}, false, true},
+	}
+	for _, test := range tests {
+		start := time.Now()
+		encoded, err := Encode(img, test.enc)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		t.Logf("%s: %d bytes as %s, quality %d, scale %.2f in %v",
+			test.name, encoded.Size(), encoded.Format, encoded.Quality, encoded.Scale, time.Since(start))
+		switch {
+		case test.fits && encoded.OverBudget:
+			t.Errorf("%s: got OverBudget, want the budget met", test.name)
+		case test.misses && !encoded.OverBudget:
+			t.Errorf("%s: got %d bytes within the budget, want OverBudget", test.name, encoded.Size())
+		case encoded.OverBudget && encoded.Scale > minScale:
+			t.Errorf("%s: got OverBudget at scale %g, want it only at %g", test.name, encoded.Scale, minScale)
+		case !encoded.OverBudget && test.enc.MaxBytes > 0 && encoded.Size() > test.enc.MaxBytes:
+			t.Errorf("%s: got %d bytes without OverBudget, over the budget of %d", test.name, encoded.Size(), test.enc.MaxBytes)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// CJKFontPaths are tried in order when a Chinese, Japanese or Korean locale is rendered and no font of the chain has
+// its glyphs, as the embedded font has none. Call AddFontFile before rendering to use another font
+var CJKFontPaths = []string{
+	"/usr/share/fonts/opentype/noto/NotoSansCJK-Regular.ttc",
+	"/usr/share/fonts/noto-cjk/NotoSansCJK-Regular.ttc",
+	"/usr/share/fonts/google-noto-cjk/NotoSansCJK-Regular.ttc",
+	"/usr/share/fonts/truetype/wqy/wqy-microhei.ttc",
+	"/usr/share/fonts/wenquanyi/wqy-microhei/wqy-microhei.ttc",
+	"/System/Library/Fonts/PingFang.ttc",
+	"/System/Library/Fonts/Hiragino Sans GB.ttc",
+	`C:\Windows\Fonts\msyh.ttc`,
+	`C:\Windows\Fonts\simsun.ttc`,
+}
+
+// A rune of every CJK script, to check whether the chain covers it.
+// Traditional Chinese has its own, as a font may only have the simplified characters
+var cjkProbes = map[string]rune{"zh": '中', "zh-hant": '們', "ja": 'あ', "ko": '한'}
+
+// Every script is searched once, the first locale of a script does not stand for the others
+var cjkOnces = map[string]*sync.Once{}
+var cjkOncesLock sync.Mutex
+
+// Font chain, the embedded font first and then user fonts in the order they were added
+var fonts []*opentype.Font
+var fontsLock sync.RWMutex
+
+// AddFont adds a TrueType/OpenType font or font collection to the fallback chain
+func AddFont(data []byte) error {
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return err
+	}
+	fontsLock.Lock()
+	defer fontsLock.Unlock()
+	for i := 0; i < collection.NumFonts(); i++ {
+		f, err := collection.Font(i)
+		if err != nil {
+			return err
+		}
+		fonts = append(fonts, f)
+	}
+	return nil
+}
+
+// AddFontFile reads a font file and adds it to the fallback chain
+func AddFontFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return AddFont(data)
+}
+
+// ensureCJK adds the first font of CJKFontPaths to the chain if the locale is a CJK one the chain does not cover,
+// it logs an error when none is found as the labels would be drawn as boxes
+func ensureCJK(locale string) {
+	script := cjkScript(locale)
+	probe, ok := cjkProbes[script]
+	if !ok {
+		return
+	}
+	cjkOncesLock.Lock()
+	once, ok := cjkOnces[script]
+	if !ok {
+		once = &sync.Once{}
+		cjkOnces[script] = once
+	}
+	cjkOncesLock.Unlock()
+	once.Do(func() {
+		if covered(probe) {
+			return
+		}
+		for _, path := range CJKFontPaths {
+			if err := AddFontFile(path); err == nil && covered(probe) {
+				slog.Info(fmt.Sprintf("Status: using %s for the %s labels", path, locale))
+				return
+			}
+		}
+		slog.Error(fmt.Sprintf("Status: no font has %s glyphs, the labels will be drawn as boxes, call renderer.AddFontFile with a CJK font", locale))
+	})
+}
+
+// cjkScript returns the key of cjkProbes for a locale, e.g. "zh-hant" for "zh-TW"
+func cjkScript(locale string) string {
+	parts := strings.Split(strings.ToLower(locale), "-")
+	if parts[0] != "zh" {
+		return parts[0]
+	}
+	for _, part := range parts[1:] {
+		switch part {
+		case "hant", "tw", "hk", "mo":
+			return "zh-hant"
+		}
+	}
+	return "zh"
+}
+
+// covered reports whether a font of the chain has a glyph for r
+func covered(r rune) bool {
+	fontsLock.RLock()
+	defer fontsLock.RUnlock()
+	var buf sfnt.Buffer
+	for _, f := range fonts {
+		if i, err := f.GlyphIndex(&buf, r); err == nil && i != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// newFace creates a face of the given size which picks the first font in the chain having a glyph for each rune.
+// Faces are not safe for concurrent use, so every render creates its own.
+func newFace(size float64) font.Face {
+	fontsLock.RLock()
+	defer fontsLock.RUnlock()
+	face := &fallbackFace{picked: map[rune]int{}}
+	for _, f := range fonts {
+		fc, err := opentype.NewFace(f, &opentype.FaceOptions{
+			Size:    size,
+			DPI:     72,
+			Hinting: font.HintingFull,
+		})
+		if err != nil {
+			continue
+		}
+		face.faces = append(face.faces, fc)
+	}
+	return face
+}
+
+// fallbackFace is a font.Face made of several faces, so gg can measure and draw mixed-font strings
+type fallbackFace struct {
+	faces  []font.Face
+	picked map[rune]int
+}
+
+// pick returns the index of the face used for r, falling back to the first one (tofu)
+func (f *fallbackFace) pick(r rune) int {
+	if i, ok := f.picked[r]; ok {
+		return i
+	}
+	i := 0
+	for j, fc := range f.faces {
+		if _, ok := fc.GlyphAdvance(r); ok {
+			i = j
+			break
+		}
+	}
+	f.picked[r] = i
+	return i
+}
+
+func (f *fallbackFace) Close() error {
+	for _, fc := range f.faces {
+		fc.Close()
+	}
+	return nil
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.faces[f.pick(r)].Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.faces[f.pick(r)].GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.faces[f.pick(r)].GlyphAdvance(r)
+}
+
+// Kern only applies between runes drawn with the same font
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	i := f.pick(r0)
+	if i != f.pick(r1) {
+		return 0
+	}
+	return f.faces[i].Kern(r0, r1)
+}
+
+// Metrics of the primary font, so line heights do not jump with the content
+func (f *fallbackFace) Metrics() font.Metrics {
+	return f.faces[0].Metrics()
+}
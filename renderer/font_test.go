@@ -0,0 +1,41 @@
+package renderer
+
+import "testing"
+
+func TestCJKScript(t *testing.T) {
+	tests := []struct {
+		locale, want string
+	}{
+		{"zh-CN", "zh"},
+		{"zh", "zh"},
+		{"zh-TW", "zh-hant"},
+		{"zh-Hant-HK", "zh-hant"},
+		{"ja-JP", "ja"},
+		{"ko", "ko"},
+		{"en", "en"},
+	}
+	for _, test := range tests {
+		if got := cjkScript(test.locale); got != test.want {
+			t.Errorf("cjkScript(%q) = %q, want %q", test.locale, got, test.want)
+		}
+	}
+}
+
+func TestEnsureCJKPerScript(t *testing.T) {
+	paths := CJKFontPaths
+	CJKFontPaths = nil
+	t.Cleanup(func() { CJKFontPaths = paths })
+
+	// A Chinese render first must not keep the others from searching for their own font
+	for _, locale := range []string{"zh-CN", "ja", "ko", "zh-TW", "en"} {
+		ensureCJK(locale)
+	}
+	for _, script := range []string{"zh", "ja", "ko", "zh-hant"} {
+		if _, ok := cjkOnces[script]; !ok {
+			t.Errorf("%s was not searched", script)
+		}
+	}
+	if _, ok := cjkOnces["en"]; ok {
+		t.Error("en was searched, it needs no CJK font")
+	}
+}
@@ -0,0 +1,28 @@
+package renderer
+
+import (
+	"image"
+	"time"
+
+	"github.com/fogleman/gg"
+	"github.com/gonebot-dev/goneplugin-status/format"
+	"github.com/gonebot-dev/goneplugin-status/i18n"
+)
+
+// DrawRenderTime writes how long the image took to render in the bottom left corner, opposite DrawAge
+func DrawRenderTime(img image.Image, d time.Duration, opts Options) image.Image {
+	scale, _ := opts.size()
+	locale := opts.Locale
+	dc := gg.NewContextForImage(img)
+	face := newFace(24 * scale)
+	defer face.Close()
+	dc.SetFontFace(face)
+	dc.SetHexColor("#FFFFFF")
+	dc.DrawStringAnchored(
+		i18n.T(locale, "render.time", format.Latency(locale, d)),
+		panelMargin*scale,
+		float64(dc.Height())-panelMargin*scale/2.0,
+		0, 0.5,
+	)
+	return dc.Image()
+}
@@ -0,0 +1,433 @@
+package renderer
+
+import (
+	"math"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// Sizes at scale 1
+const shadowOffset float64 = 10
+const badgePaddingX float64 = 48
+const badgePaddingY float64 = 24
+const badgeMargin float64 = 32
+const panelPadding float64 = 48
+const panelMargin float64 = 48
+const panelRadius float64 = 32
+const titleSize float64 = 64
+const contentSize float64 = 36
+
+// metrics are the sizes scaled for one render
+type metrics struct {
+	scale         float64
+	shadowOffset  float64
+	badgePaddingX float64
+	badgePaddingY float64
+	badgeMargin   float64
+	panelPadding  float64
+	panelMargin   float64
+	panelRadius   float64
+}
+
+func scaledMetrics(scale float64) metrics {
+	return metrics{
+		scale:         scale,
+		shadowOffset:  shadowOffset * scale,
+		badgePaddingX: badgePaddingX * scale,
+		badgePaddingY: badgePaddingY * scale,
+		badgeMargin:   badgeMargin * scale,
+		panelPadding:  panelPadding * scale,
+		panelMargin:   panelMargin * scale,
+		panelRadius:   panelRadius * scale,
+	}
+}
+
+// drawer holds the state of one render
+type drawer struct {
+	dc *gg.Context
+	m  metrics
+
+	titleFont         font.Face
+	contentFont       font.Face
+	titleLineHeight   float64
+	contentLineHeight float64
+}
+
+func newDrawer(scale float64) *drawer {
+	d := &drawer{
+		dc:          gg.NewContext(0, 0),
+		m:           scaledMetrics(scale),
+		titleFont:   newFace(titleSize * scale),
+		contentFont: newFace(contentSize * scale),
+	}
+	d.dc.SetFontFace(d.titleFont)
+	_, d.titleLineHeight = d.dc.MeasureString("T")
+	d.dc.SetFontFace(d.contentFont)
+	_, d.contentLineHeight = d.dc.MeasureString("T")
+	return d
+}
+
+// measure returns the width of str in the given font
+func (d *drawer) measure(str string, title bool) float64 {
+	d.font(title)
+	w, _ := d.dc.MeasureString(str)
+	return w
+}
+
+func (d *drawer) font(title bool) {
+	if title {
+		d.dc.SetFontFace(d.titleFont)
+	} else {
+		d.dc.SetFontFace(d.contentFont)
+	}
+}
+
+// fit shortens str with an ellipsis until it is at most maxWidth wide
+func (d *drawer) fit(str string, title bool, maxWidth float64) string {
+	if d.measure(str, title) <= maxWidth {
+		return str
+	}
+	runes := []rune(str)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if d.measure(string(runes)+"…", title) <= maxWidth {
+			break
+		}
+	}
+	return string(runes) + "…"
+}
+
+// roundedBox draws a rounded rectangle with its shadow
+func (d *drawer) roundedBox(x, y, w, h, r float64, color string) {
+	d.dc.SetHexColor(shadow)
+	d.dc.DrawRoundedRectangle(x+d.m.shadowOffset, y+d.m.shadowOffset, w, h, r)
+	d.dc.Fill()
+	d.dc.SetHexColor(color)
+	d.dc.DrawRoundedRectangle(x, y, w, h, r)
+	d.dc.Fill()
+}
+
+// element is a row inside a panel
+type element interface {
+	height(d *drawer, width float64) float64
+	draw(d *drawer, x, y, width float64)
+	// view returns the element as data, see Panels
+	view() ElementView
+}
+
+// panel is a translucent box stacking its elements
+type panel struct {
+	elements []element
+	// Compact panels flow into a grid when the canvas is wide enough
+	compact bool
+	// Section shown, see Options.Section
+	section string
+}
+
+func (p panel) height(d *drawer, width float64) float64 {
+	h := d.m.panelPadding * 2
+	inner := width - d.m.panelPadding*2
+	for i, e := range p.elements {
+		if i > 0 {
+			h += d.m.badgeMargin
+		}
+		h += e.height(d, inner)
+	}
+	return h
+}
+
+// draw draws the panel stretched to height, which is at least its own height
+func (p panel) draw(d *drawer, x, y, width, height float64) {
+	d.roundedBox(x, y, width, height, d.m.panelRadius, panelColor)
+	inner := width - d.m.panelPadding*2
+	y += d.m.panelPadding
+	for _, e := range p.elements {
+		e.draw(d, x+d.m.panelPadding, y, inner)
+		y += e.height(d, inner) + d.m.badgeMargin
+	}
+}
+
+// placement is a panel placed on the canvas
+type placement struct {
+	panel
+	x, y, width, height float64
+}
+
+// Narrowest column of the grid at scale 1
+const minColumnWidth float64 = 520
+
+// Most columns of the grid
+const maxColumns = 3
+
+// columnsFor picks how many columns count compact panels take in width, at most columns if it is not 0
+func (d *drawer) columnsFor(width float64, count int, columns int) int {
+	if columns <= 0 {
+		columns = int((width + d.m.panelMargin) / (minColumnWidth*d.m.scale + d.m.panelMargin))
+		columns = min(columns, maxColumns)
+	}
+	return max(min(columns, count), 1)
+}
+
+// layout places panels from top to bottom, runs of compact panels going into a grid.
+// It returns the placements and the height of the canvas.
+func (d *drawer) layout(panels []panel, width float64, columns int) (placements []placement, height float64) {
+	panelWidth := width - d.m.panelMargin*2
+	y := d.m.panelMargin
+	for i := 0; i < len(panels); {
+		if !panels[i].compact {
+			h := panels[i].height(d, panelWidth)
+			placements = append(placements, placement{panels[i], d.m.panelMargin, y, panelWidth, h})
+			y += h + d.m.panelMargin
+			i++
+			continue
+		}
+		// Collect the run of compact panels
+		j := i
+		for j < len(panels) && panels[j].compact {
+			j++
+		}
+		cols := d.columnsFor(width, j-i, columns)
+		cellWidth := (panelWidth - d.m.panelMargin*float64(cols-1)) / float64(cols)
+		for row := i; row < j; row += cols {
+			// Panels of a row share the height of the tallest one
+			rowHeight := 0.0
+			for k := row; k < min(row+cols, j); k++ {
+				rowHeight = max(rowHeight, panels[k].height(d, cellWidth))
+			}
+			for k := row; k < min(row+cols, j); k++ {
+				x := d.m.panelMargin + float64(k-row)*(cellWidth+d.m.panelMargin)
+				placements = append(placements, placement{panels[k], x, y, cellWidth, rowHeight})
+			}
+			y += rowHeight + d.m.panelMargin
+		}
+		i = j
+	}
+	return placements, y
+}
+
+// badge is a pill with white text
+type badge struct {
+	text  string
+	color string
+}
+
+// badgeRow flows badges left to right, wrapping them when the row is full
+type badgeRow struct {
+	badges []badge
+	title  bool
+	center bool
+}
+
+func (b badgeRow) badgeHeight(d *drawer) float64 {
+	if b.title {
+		return d.titleLineHeight + d.m.badgePaddingY*3
+	}
+	return d.contentLineHeight + d.m.badgePaddingY*2
+}
+
+// lines splits the badges into lines, each badge along with its text fitted to the width
+func (b badgeRow) lines(d *drawer, width float64) (lines [][]badge, widths [][]float64) {
+	var line []badge
+	var lineWidths []float64
+	used := 0.0
+	for _, bdg := range b.badges {
+		bdg.text = d.fit(bdg.text, b.title, width-d.m.badgePaddingX*2)
+		w := d.measure(bdg.text, b.title) + d.m.badgePaddingX*2
+		if len(line) > 0 && used+d.m.badgeMargin+w > width {
+			lines, widths = append(lines, line), append(widths, lineWidths)
+			line, lineWidths, used = nil, nil, 0
+		}
+		if len(line) > 0 {
+			used += d.m.badgeMargin
+		}
+		line, lineWidths = append(line, bdg), append(lineWidths, w)
+		used += w
+	}
+	if len(line) > 0 {
+		lines, widths = append(lines, line), append(widths, lineWidths)
+	}
+	return
+}
+
+func (b badgeRow) height(d *drawer, width float64) float64 {
+	lines, _ := b.lines(d, width)
+	n := float64(len(lines))
+	return n*b.badgeHeight(d) + math.Max(n-1, 0)*d.m.badgeMargin
+}
+
+func (b badgeRow) draw(d *drawer, x, y, width float64) {
+	lines, widths := b.lines(d, width)
+	h := b.badgeHeight(d)
+	lineHeight := d.contentLineHeight
+	if b.title {
+		lineHeight = d.titleLineHeight
+	}
+	for i, line := range lines {
+		lineWidth := -d.m.badgeMargin
+		for _, w := range widths[i] {
+			lineWidth += w + d.m.badgeMargin
+		}
+		bx := x
+		if b.center {
+			bx = x + (width-lineWidth)/2.0
+		}
+		for j, bdg := range line {
+			d.roundedBox(bx, y, widths[i][j], h, h/2.0, bdg.color)
+			d.font(b.title)
+			d.dc.SetHexColor("#FFFFFF")
+			d.dc.DrawString(bdg.text, bx+d.m.badgePaddingX, y+(h+lineHeight)/2.0)
+			bx += widths[i][j] + d.m.badgeMargin
+		}
+		y += h + d.m.badgeMargin
+	}
+}
+
+// splitRow shares the width equally between badges with centered text, stacking them when it is too narrow
+type splitRow struct {
+	badges []badge
+}
+
+func (s splitRow) stacked(d *drawer, width float64) bool {
+	n := float64(len(s.badges))
+	w := (width - d.m.badgeMargin*(n-1)) / n
+	for _, bdg := range s.badges {
+		if d.measure(bdg.text, false)+d.m.badgePaddingX > w {
+			return true
+		}
+	}
+	return false
+}
+
+func (s splitRow) badgeHeight(d *drawer) float64 {
+	return d.contentLineHeight + d.m.badgePaddingY*2
+}
+
+func (s splitRow) height(d *drawer, width float64) float64 {
+	if s.stacked(d, width) {
+		n := float64(len(s.badges))
+		return n*s.badgeHeight(d) + (n-1)*d.m.badgeMargin
+	}
+	return s.badgeHeight(d)
+}
+
+func (s splitRow) draw(d *drawer, x, y, width float64) {
+	n := float64(len(s.badges))
+	w := (width - d.m.badgeMargin*(n-1)) / n
+	stacked := s.stacked(d, width)
+	if stacked {
+		w = width
+	}
+	h := s.badgeHeight(d)
+	for _, bdg := range s.badges {
+		d.roundedBox(x, y, w, h, h/2.0, bdg.color)
+		d.font(false)
+		d.dc.SetHexColor("#FFFFFF")
+		d.dc.DrawStringAnchored(d.fit(bdg.text, false, w-d.m.badgePaddingX), x+w/2.0, y+h/2.0, 0.5, 0.5)
+		if stacked {
+			y += h + d.m.badgeMargin
+		} else {
+			x += w + d.m.badgeMargin
+		}
+	}
+}
+
+// barRow is a progress bar with its label on the left and a caption inside,
+// the caption goes above the bar when it does not fit
+type barRow struct {
+	label   string
+	caption string
+	percent float64
+	color   string
+}
+
+// geometry returns the bar's offset and width, and whether the caption is inside
+func (b barRow) geometry(d *drawer, width float64) (offset float64, barWidth float64, inside bool) {
+	offset = math.Max(d.measure(b.label, false), d.measure("100.0%", false)) + d.m.badgeMargin
+	barWidth = width - offset
+	barHeight := d.contentLineHeight + d.m.badgePaddingY
+	inside = d.measure(b.caption, false) <= barWidth-barHeight
+	return
+}
+
+func (b barRow) height(d *drawer, width float64) float64 {
+	h := d.contentLineHeight + d.m.badgePaddingY
+	if _, _, inside := b.geometry(d, width); !inside {
+		h += d.contentLineHeight + d.m.badgePaddingY/2.0
+	}
+	return h
+}
+
+func (b barRow) draw(d *drawer, x, y, width float64) {
+	offset, barWidth, inside := b.geometry(d, width)
+	h := d.contentLineHeight + d.m.badgePaddingY
+	d.font(false)
+	if !inside {
+		d.dc.SetHexColor("#000000")
+		d.dc.DrawStringAnchored(d.fit(b.caption, false, width), x+width/2.0, y+d.contentLineHeight/2.0, 0.5, 0.5)
+		y += d.contentLineHeight + d.m.badgePaddingY/2.0
+	}
+	barX := x + offset
+	b.drawBar(d, barX, y, barWidth)
+	d.font(false)
+	d.dc.SetHexColor("#000000")
+	d.dc.DrawStringAnchored(b.label, x, y+h/2.0, 0, 0.5)
+	if inside {
+		d.dc.DrawStringAnchored(b.caption, barX+barWidth/2.0, y+h/2.0, 0.5, 0.5)
+	}
+}
+
+// drawBar draws the bar alone
+func (b barRow) drawBar(d *drawer, x, y, width float64) {
+	h := d.contentLineHeight + d.m.badgePaddingY
+	d.roundedBox(x, y, width, h, h/2.0, panelColor)
+	if fill := width * math.Min(math.Max(b.percent, 0), 100) / 100.0; fill > 0 {
+		d.dc.SetHexColor(b.color)
+		d.dc.DrawRoundedRectangle(x, y, math.Max(fill, h), h, h/2.0)
+		d.dc.Fill()
+	}
+}
+
+// row puts elements side by side, sharing the width by their weights
+type row struct {
+	elements []element
+	// Weight of each element, 1 when missing
+	weights []float64
+}
+
+// widths returns the width of each element
+func (r row) widths(d *drawer, width float64) (widths []float64) {
+	total := 0.0
+	for i := range r.elements {
+		total += r.weight(i)
+	}
+	free := width - d.m.badgeMargin*float64(len(r.elements)-1)
+	for i := range r.elements {
+		widths = append(widths, free*r.weight(i)/total)
+	}
+	return
+}
+
+func (r row) weight(i int) float64 {
+	if i < len(r.weights) && r.weights[i] > 0 {
+		return r.weights[i]
+	}
+	return 1
+}
+
+func (r row) height(d *drawer, width float64) (h float64) {
+	for i, w := range r.widths(d, width) {
+		h = max(h, r.elements[i].height(d, w))
+	}
+	return
+}
+
+// draw centers every element vertically in the row
+func (r row) draw(d *drawer, x, y, width float64) {
+	h := r.height(d, width)
+	for i, w := range r.widths(d, width) {
+		e := r.elements[i]
+		e.draw(d, x, y+(h-e.height(d, w))/2.0, w)
+		x += w + d.m.badgeMargin
+	}
+}
@@ -0,0 +1,50 @@
+package renderer
+
+import (
+	"github.com/gonebot-dev/goneplugin-status/format"
+	"github.com/gonebot-dev/goneplugin-status/i18n"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+)
+
+// leaderboardPanels lays out the activity view, a panel for each of the top groups, users and plugins
+func leaderboardPanels(info sysinfo.SysInfo, loc string) (panels []panel) {
+	a := info.Activity
+	if a == nil {
+		return []panel{{elements: []element{
+			badgeRow{center: true, badges: []badge{{"● " + i18n.T(loc, "leaderboard.off"), darkBadge}}},
+		}}}
+	}
+	for _, board := range []struct {
+		key      string
+		rankings []sysinfo.Ranking
+		total    int64
+		color    string
+	}{
+		{"leaderboard.groups", a.Groups, a.GroupTotal, golangBlue},
+		{"leaderboard.users", a.Users, a.UserTotal, warning},
+		{"leaderboard.plugins", a.Plugins, a.PluginTotal, success},
+	} {
+		if len(board.rankings) == 0 {
+			continue
+		}
+		p := panel{elements: []element{
+			badgeRow{center: true, badges: []badge{{"● " + i18n.T(loc, board.key), board.color}}},
+		}}
+		for i, r := range board.rankings {
+			share := float64(r.Count) / float64(board.total) * 100
+			p.elements = append(p.elements, barRow{
+				label:   format.Percent(loc, share),
+				caption: i18n.T(loc, "leaderboard.item", i+1, r.Name, i18n.Number(loc, float64(r.Count), 0)),
+				percent: share,
+				color:   board.color,
+			})
+		}
+		panels = append(panels, p)
+	}
+	if len(panels) == 0 {
+		panels = append(panels, panel{elements: []element{
+			badgeRow{center: true, badges: []badge{{"● " + i18n.T(loc, "leaderboard.empty"), darkBadge}}},
+		}})
+	}
+	return
+}
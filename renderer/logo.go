@@ -0,0 +1,40 @@
+package renderer
+
+import "strings"
+
+type distro struct {
+	// Display name
+	Name string
+	// Nerd Font glyph
+	Logo string
+}
+
+// Distros by gopsutil platform, or by GOOS for the systems without one
+var distros = map[string]distro{
+	"ubuntu":  {Name: "Ubuntu", Logo: "\uf31b"},
+	"debian":  {Name: "Debian", Logo: "\uf306"},
+	"arch":    {Name: "Arch Linux", Logo: "\uf303"},
+	"fedora":  {Name: "Fedora", Logo: "\uf30a"},
+	"alpine":  {Name: "Alpine", Logo: "\uf300"},
+	"nixos":   {Name: "NixOS", Logo: "\uf313"},
+	"darwin":  {Name: "macOS", Logo: "\uf179"},
+	"windows": {Name: "Windows", Logo: "\uf17a"},
+	"freebsd": {Name: "FreeBSD", Logo: "\uf30c"},
+	"linux":   {Name: "Linux", Logo: "\uf17c"},
+}
+
+// distroOf returns the display name and logo of a platform, falling back to its OS
+func distroOf(platform string, os string) distro {
+	if d, ok := distros[strings.ToLower(platform)]; ok {
+		return d
+	}
+	d, ok := distros[os]
+	if !ok {
+		return distro{Name: os, Logo: "\ue62a"}
+	}
+	// Unlisted Linux distros keep their own name with Tux, Windows reports its product name
+	if platform != "" && os != "windows" {
+		d.Name = platform
+	}
+	return d
+}
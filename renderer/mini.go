@@ -0,0 +1,73 @@
+package renderer
+
+import (
+	"github.com/gonebot-dev/goneplugin-status/format"
+	"github.com/gonebot-dev/goneplugin-status/i18n"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+	"github.com/gonebot-dev/goneplugin-status/threshold"
+)
+
+// Views
+const (
+	// ViewFull shows every section
+	ViewFull = "full"
+	// ViewMini is a single strip with CPU, memory and the root disk
+	ViewMini = "mini"
+	// ViewActivity ranks the groups, users and plugins driving the traffic
+	ViewActivity = "activity"
+)
+
+// Panel padding and margin of the mini card at scale 1, a strip of about 1280x300 needs less than the full view
+const miniPanelPadding float64 = 16
+const miniPanelMargin float64 = 24
+
+// miniMetrics returns the metrics of the mini card
+func miniMetrics(m metrics) metrics {
+	m.panelPadding = miniPanelPadding * m.scale
+	m.panelMargin = miniPanelMargin * m.scale
+	return m
+}
+
+// miniPanels lays out the mini card, a single panel with a row of ring gauges
+func miniPanels(info sysinfo.SysInfo, loc string) []panel {
+	d := distroOf(info.Platform, info.OS)
+	elements := []element{
+		badgeRow{badges: []badge{{d.Logo + " " + d.Name, golangBlue}}},
+		gauge{
+			label:   "CPU",
+			value:   format.Percent(loc, info.CpuUsedPercent),
+			percent: info.CpuUsedPercent,
+			color:   threshold.For(threshold.CPU).Level(info.CpuUsedPercent).Color,
+		},
+		gauge{
+			label:   i18n.T(loc, "mini.memory"),
+			value:   format.Percent(loc, info.MemUsedPercent),
+			percent: info.MemUsedPercent,
+			color:   threshold.For(threshold.Memory).Level(info.MemUsedPercent).Color,
+		},
+	}
+	weights := []float64{1.2, 1, 1}
+	if disk, ok := rootDisk(info); ok {
+		elements = append(elements, gauge{
+			label:   i18n.T(loc, "mini.disk"),
+			value:   format.Percent(loc, disk.UsedPercent),
+			percent: disk.UsedPercent,
+			color:   threshold.ForMount(disk.Mount()).Level(disk.UsedPercent).Color,
+		})
+		weights = append(weights, 1)
+	}
+	return []panel{{elements: []element{row{elements: elements, weights: weights}}}}
+}
+
+// rootDisk returns the disk mounted at "/", or the first one on systems without it
+func rootDisk(info sysinfo.SysInfo) (sysinfo.DiskInfo, bool) {
+	for _, disk := range info.Disks {
+		if disk.Mount() == "/" {
+			return disk, true
+		}
+	}
+	if len(info.Disks) > 0 {
+		return info.Disks[0], true
+	}
+	return sysinfo.DiskInfo{}, false
+}
@@ -0,0 +1,27 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+)
+
+func TestMiniSize(t *testing.T) {
+	tests := []struct {
+		name string
+		info func() sysinfo.SysInfo
+		opts Options
+		// The card is about 1280x300 at scale 1
+		width, maxHeight int
+	}{
+		{"scale 1", card, Options{View: ViewMini}, 1280, 300},
+		{"scale 2", card, Options{View: ViewMini, Scale: 2}, 2560, 600},
+		{"no disk", func() sysinfo.SysInfo { info := card(); info.Disks = nil; return info }, Options{View: ViewMini}, 1280, 300},
+	}
+	for _, test := range tests {
+		b := RenderImage(test.info(), test.opts).Bounds()
+		if b.Dx() != test.width || b.Dy() > test.maxHeight {
+			t.Errorf("%s: got %dx%d, want %d wide and at most %d high", test.name, b.Dx(), b.Dy(), test.width, test.maxHeight)
+		}
+	}
+}
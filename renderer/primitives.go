@@ -0,0 +1,306 @@
+package renderer
+
+import (
+	"math"
+
+	"github.com/fogleman/gg"
+)
+
+// Theme holds the colors shared by the drawing primitives
+type Theme struct {
+	// Drop shadow, drawn ShadowOffset pixels to the bottom right
+	Shadow       string
+	ShadowOffset float64
+	// Unfilled part of gauges and bars
+	Track string
+	// Colors given to slices without their own, in order
+	Palette []string
+}
+
+// DefaultTheme matches the status card
+var DefaultTheme = Theme{
+	Shadow:       shadow,
+	ShadowOffset: shadowOffset,
+	Track:        panelColor,
+	Palette:      []string{golangBlue, success, warning, danger, "#909399C0", "#9B59B6C0"},
+}
+
+// Slice is a part of a donut chart or stacked bar
+type Slice struct {
+	Value float64 `json:"value"`
+	// Hex color, the theme's palette is used when empty
+	Color string `json:"color,omitempty"`
+}
+
+func (t Theme) color(i int, color string) string {
+	if color != "" || len(t.Palette) == 0 {
+		return color
+	}
+	return t.Palette[i%len(t.Palette)]
+}
+
+// RingGauge draws a ring centered at (cx, cy) filled clockwise from the top to percent
+func RingGauge(dc *gg.Context, cx, cy, radius, thickness, percent float64, color string, theme Theme) {
+	percent = math.Min(math.Max(percent, 0), 100)
+	Donut(dc, cx, cy, radius, thickness, []Slice{
+		{Value: percent, Color: color},
+		{Value: 100 - percent, Color: theme.Track},
+	}, theme)
+}
+
+// Donut draws a donut chart centered at (cx, cy), slices going clockwise from the top
+func Donut(dc *gg.Context, cx, cy, radius, thickness float64, slices []Slice, theme Theme) {
+	total := 0.0
+	for _, slice := range slices {
+		total += math.Max(slice.Value, 0)
+	}
+	mid := radius - thickness/2.0
+	dc.SetLineWidth(thickness)
+	dc.SetLineCapButt()
+
+	// Shadow
+	dc.SetHexColor(theme.Shadow)
+	dc.DrawCircle(cx+theme.ShadowOffset, cy+theme.ShadowOffset, mid)
+	dc.Stroke()
+	if total <= 0 {
+		dc.SetHexColor(theme.Track)
+		dc.DrawCircle(cx, cy, mid)
+		dc.Stroke()
+		return
+	}
+
+	angle := -math.Pi / 2
+	for i, slice := range slices {
+		if slice.Value <= 0 {
+			continue
+		}
+		sweep := 2 * math.Pi * slice.Value / total
+		dc.SetHexColor(theme.color(i, slice.Color))
+		dc.NewSubPath()
+		dc.DrawArc(cx, cy, mid, angle, angle+sweep)
+		dc.Stroke()
+		angle += sweep
+	}
+}
+
+// StackedBar draws a rounded horizontal bar split into slices, scaled so they fill it
+func StackedBar(dc *gg.Context, x, y, width, height float64, slices []Slice, theme Theme) {
+	radius := height / 2.0
+	dc.SetHexColor(theme.Shadow)
+	dc.DrawRoundedRectangle(x+theme.ShadowOffset, y+theme.ShadowOffset, width, height, radius)
+	dc.Fill()
+	dc.SetHexColor(theme.Track)
+	dc.DrawRoundedRectangle(x, y, width, height, radius)
+	dc.Fill()
+
+	total := 0.0
+	for _, slice := range slices {
+		total += math.Max(slice.Value, 0)
+	}
+	if total <= 0 {
+		return
+	}
+	// Clip to the bar so only its ends are rounded
+	dc.Push()
+	dc.DrawRoundedRectangle(x, y, width, height, radius)
+	dc.Clip()
+	for i, slice := range slices {
+		if slice.Value <= 0 {
+			continue
+		}
+		w := width * slice.Value / total
+		dc.SetHexColor(theme.color(i, slice.Color))
+		dc.DrawRectangle(x, y, w, height)
+		dc.Fill()
+		x += w
+	}
+	dc.ResetClip()
+	dc.Pop()
+}
+
+// Sparkline draws values as a line with a translucent area below, scaled between lo and hi.
+// Values are scaled from 0 to max(values) when lo equals hi.
+func Sparkline(dc *gg.Context, x, y, width, height float64, values []float64, lo, hi float64, color string, theme Theme) {
+	if len(values) == 0 {
+		return
+	}
+	if lo == hi {
+		lo = 0
+		for _, v := range values {
+			hi = math.Max(hi, v)
+		}
+		if hi == 0 {
+			hi = 1
+		}
+	}
+	point := func(i int) (float64, float64) {
+		px := x
+		if len(values) > 1 {
+			px += width * float64(i) / float64(len(values)-1)
+		}
+		v := math.Min(math.Max(values[i], lo), hi)
+		return px, y + height - height*(v-lo)/(hi-lo)
+	}
+
+	// Area
+	dc.NewSubPath()
+	dc.MoveTo(x, y+height)
+	for i := range values {
+		dc.LineTo(point(i))
+	}
+	px, _ := point(len(values) - 1)
+	dc.LineTo(px, y+height)
+	dc.ClosePath()
+	dc.SetHexColor(theme.Track)
+	dc.Fill()
+
+	// Line
+	dc.NewSubPath()
+	for i := range values {
+		dc.LineTo(point(i))
+	}
+	dc.SetHexColor(color)
+	dc.SetLineWidth(math.Max(height/24.0, 1))
+	dc.SetLineCapRound()
+	dc.SetLineJoinRound()
+	dc.Stroke()
+}
+
+// theme returns the default theme scaled for the drawer
+func (d *drawer) theme() Theme {
+	theme := DefaultTheme
+	theme.ShadowOffset = d.m.shadowOffset
+	return theme
+}
+
+// gauge is a ring gauge with its value inside and label below
+type gauge struct {
+	label   string
+	value   string
+	percent float64
+	color   string
+}
+
+func (g gauge) diameter(d *drawer) float64 {
+	return d.contentLineHeight*2 + d.m.badgePaddingY*2.5
+}
+
+func (g gauge) thickness(d *drawer) float64 {
+	return d.m.badgePaddingY / 2.0
+}
+
+func (g gauge) height(d *drawer, width float64) float64 {
+	return g.diameter(d) + d.m.badgePaddingY/3.0 + d.contentLineHeight
+}
+
+func (g gauge) draw(d *drawer, x, y, width float64) {
+	radius := math.Min(g.diameter(d), width) / 2.0
+	cx, cy := x+width/2.0, y+g.diameter(d)/2.0
+	RingGauge(d.dc, cx, cy, radius, g.thickness(d), g.percent, g.color, d.theme())
+	d.font(false)
+	d.dc.SetHexColor("#000000")
+	// The value takes the inside of the ring, e.g. "37.5%"
+	d.dc.DrawStringAnchored(d.fit(g.value, false, (radius-g.thickness(d))*2-d.m.badgePaddingY/3.0), cx, cy, 0.5, 0.5)
+	d.dc.DrawStringAnchored(d.fit(g.label, false, width), cx, y+g.height(d, width)-d.contentLineHeight/2.0, 0.5, 0.5)
+}
+
+// stackedBar is a stacked bar with a caption inside
+type stackedBar struct {
+	slices  []Slice
+	caption string
+}
+
+func (s stackedBar) height(d *drawer, width float64) float64 {
+	return d.contentLineHeight + d.m.badgePaddingY
+}
+
+func (s stackedBar) draw(d *drawer, x, y, width float64) {
+	h := s.height(d, width)
+	StackedBar(d.dc, x, y, width, h, s.slices, d.theme())
+	d.font(false)
+	d.dc.SetHexColor("#000000")
+	d.dc.DrawStringAnchored(d.fit(s.caption, false, width-h), x+width/2.0, y+h/2.0, 0.5, 0.5)
+}
+
+// sparkline is a line chart of recent values between lo and hi
+type sparkline struct {
+	values []float64
+	lo, hi float64
+	color  string
+}
+
+func (s sparkline) height(d *drawer, width float64) float64 {
+	return d.contentLineHeight*2 + d.m.badgePaddingY
+}
+
+func (s sparkline) draw(d *drawer, x, y, width float64) {
+	Sparkline(d.dc, x, y, width, s.height(d, width), s.values, s.lo, s.hi, s.color, d.theme())
+}
+
+// BarChart draws values as bars from the bottom, scaled from 0 to max(values).
+// The bars leave a gap of a quarter of their width between them
+func BarChart(dc *gg.Context, x, y, width, height float64, values []float64, color string, theme Theme) {
+	if len(values) == 0 {
+		return
+	}
+	top := 0.0
+	for _, v := range values {
+		top = math.Max(top, v)
+	}
+	step := width / float64(len(values))
+	gap := step / 4.0
+	for i, v := range values {
+		h := 0.0
+		if top > 0 {
+			h = height * math.Max(v, 0) / top
+		}
+		bx := x + float64(i)*step + gap/2.0
+		// The track shows the scale behind every bar
+		dc.SetHexColor(theme.Track)
+		dc.DrawRectangle(bx, y, step-gap, height)
+		dc.Fill()
+		if h > 0 {
+			dc.SetHexColor(color)
+			dc.DrawRectangle(bx, y+height-h, step-gap, h)
+			dc.Fill()
+		}
+	}
+}
+
+// histogram is a bar chart with labels under some bars
+type histogram struct {
+	values []float64
+	// Label of each bar, empty ones are skipped
+	labels []string
+	color  string
+}
+
+func (h histogram) chartHeight(d *drawer) float64 {
+	return d.contentLineHeight*3 + d.m.badgePaddingY*2
+}
+
+func (h histogram) height(d *drawer, width float64) float64 {
+	return h.chartHeight(d) + d.m.badgePaddingY/2.0 + d.contentLineHeight
+}
+
+func (h histogram) draw(d *drawer, x, y, width float64) {
+	BarChart(d.dc, x, y, width, h.chartHeight(d), h.values, h.color, d.theme())
+	d.font(false)
+	d.dc.SetHexColor("#000000")
+	step := width / float64(max(len(h.values), 1))
+	// Right edge of the last label drawn, labels running into it are skipped
+	edge := math.Inf(-1)
+	for i, label := range h.labels {
+		if label == "" {
+			continue
+		}
+		// Keep the labels at the ends inside the panel
+		w := d.measure(label, false)
+		lx := math.Min(math.Max(x+step*(float64(i)+0.5), x+w/2.0), x+width-w/2.0)
+		if lx-w/2.0 < edge+d.m.badgeMargin {
+			continue
+		}
+		d.dc.DrawStringAnchored(label, lx, y+h.height(d, width)-d.contentLineHeight/2.0, 0.5, 0.5)
+		edge = lx + w/2.0
+	}
+}
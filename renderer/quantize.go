@@ -0,0 +1,166 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// colorCount is a color with 5 bits per channel and how many pixels have it
+type colorCount struct {
+	c     [4]uint8
+	count int
+}
+
+// adaptivePalette picks up to n colors for img by median cut, so the palette follows the image instead of a fixed one
+func adaptivePalette(img image.Image, n int) color.Palette {
+	counts := map[[4]uint8]int{}
+	b := img.Bounds()
+	if rgba, ok := img.(*image.RGBA); ok {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			row := rgba.Pix[rgba.PixOffset(b.Min.X, y):rgba.PixOffset(b.Max.X, y)]
+			for i := 0; i < len(row); i += 4 {
+				counts[[4]uint8{row[i] >> 3, row[i+1] >> 3, row[i+2] >> 3, row[i+3] >> 3}]++
+			}
+		}
+	} else {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				r, g, bl, a := img.At(x, y).RGBA()
+				counts[[4]uint8{uint8(r >> 11), uint8(g >> 11), uint8(bl >> 11), uint8(a >> 11)}]++
+			}
+		}
+	}
+	colors := make([]colorCount, 0, len(counts))
+	for c, count := range counts {
+		colors = append(colors, colorCount{c, count})
+	}
+
+	boxes := [][]colorCount{colors}
+	for len(boxes) < n {
+		// Split the box spanning the widest channel, at the median pixel
+		widest, channel, span := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			for ch := 0; ch < 4; ch++ {
+				lo, hi := box[0].c[ch], box[0].c[ch]
+				for _, cc := range box {
+					lo, hi = min(lo, cc.c[ch]), max(hi, cc.c[ch])
+				}
+				if int(hi-lo) > span {
+					widest, channel, span = i, ch, int(hi-lo)
+				}
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		box := boxes[widest]
+		sort.Slice(box, func(i, j int) bool { return box[i].c[channel] < box[j].c[channel] })
+		total := 0
+		for _, cc := range box {
+			total += cc.count
+		}
+		split, seen := 1, box[0].count
+		for split < len(box)-1 && seen+box[split].count <= total/2 {
+			seen += box[split].count
+			split++
+		}
+		boxes[widest] = box[:split]
+		boxes = append(boxes, box[split:])
+	}
+
+	result := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		var sum [4]int
+		total := 0
+		for _, cc := range box {
+			for ch := range sum {
+				sum[ch] += int(cc.c[ch]) * cc.count
+			}
+			total += cc.count
+		}
+		if total == 0 {
+			continue
+		}
+		var avg [4]uint8
+		for ch := range avg {
+			v := uint8(sum[ch] / total)
+			avg[ch] = v<<3 | v>>2
+		}
+		// Premultiplied colors never exceed their alpha
+		result = append(result, color.RGBA{min(avg[0], avg[3]), min(avg[1], avg[3]), min(avg[2], avg[3]), avg[3]})
+	}
+	if len(result) == 0 {
+		result = append(result, color.RGBA{})
+	}
+	return result
+}
+
+// dither maps img to a palette with Floyd-Steinberg error diffusion like draw.FloydSteinberg.
+// Searching the palette for each pixel is slow, so the nearest color is cached by 5 bits per channel
+// like the histogram of adaptivePalette, the error diffusion makes up for the lost bits
+func dither(img image.Image, p color.Palette) *image.Paletted {
+	b := img.Bounds()
+	dst := image.NewPaletted(b, p)
+	colors := make([][4]int32, len(p))
+	for i, c := range p {
+		r, g, bl, a := c.RGBA()
+		colors[i] = [4]int32{int32(r >> 8), int32(g >> 8), int32(bl >> 8), int32(a >> 8)}
+	}
+	// Index plus one, 0 when not searched yet
+	nearest := make([]uint16, 1<<20)
+	w := b.Dx()
+	// Errors of the current and next rows in sixteenths, with a pixel of padding on both sides
+	cur, next := make([][4]int32, w+2), make([][4]int32, w+2)
+	rgba, isRGBA := img.(*image.RGBA)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := 0; x < w; x++ {
+			var src [4]int32
+			if isRGBA {
+				i := rgba.PixOffset(b.Min.X+x, y)
+				src = [4]int32{int32(rgba.Pix[i]), int32(rgba.Pix[i+1]), int32(rgba.Pix[i+2]), int32(rgba.Pix[i+3])}
+			} else {
+				r, g, bl, a := img.At(b.Min.X+x, y).RGBA()
+				src = [4]int32{int32(r >> 8), int32(g >> 8), int32(bl >> 8), int32(a >> 8)}
+			}
+			var c [4]int32
+			for ch := range c {
+				c[ch] = min(max(src[ch]+(cur[x+1][ch]+8)/16, 0), 255)
+			}
+			key := c[0]>>3<<15 | c[1]>>3<<10 | c[2]>>3<<5 | c[3]>>3
+			index := uint8(nearest[key] - 1)
+			if nearest[key] == 0 {
+				// Search from the middle of the shade
+				var mid [4]int32
+				for ch := range c {
+					mid[ch] = c[ch]&^7 | 4
+				}
+				best := int64(-1)
+				for i, pc := range colors {
+					var d int64
+					for ch := range mid {
+						d += int64(mid[ch]-pc[ch]) * int64(mid[ch]-pc[ch])
+					}
+					if best < 0 || d < best {
+						best, index = d, uint8(i)
+					}
+				}
+				nearest[key] = uint16(index) + 1
+			}
+			dst.Pix[dst.PixOffset(b.Min.X+x, y)] = index
+			for ch := range c {
+				e := c[ch] - colors[index][ch]
+				cur[x+2][ch] += e * 7
+				next[x][ch] += e * 3
+				next[x+1][ch] += e * 5
+				next[x+2][ch] += e
+			}
+		}
+		cur, next = next, cur
+		clear(next)
+	}
+	return dst
+}
@@ -1,46 +1,53 @@
 package renderer
 
 import (
-	"bytes"
 	"embed"
 	"encoding/base64"
 	"fmt"
 	"image"
 	_ "image/png"
-	"io"
-	"runtime"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/fogleman/gg"
-	"github.com/golang/freetype"
-	"github.com/golang/freetype/truetype"
+	"github.com/gonebot-dev/goneplugin-status/format"
+	"github.com/gonebot-dev/goneplugin-status/i18n"
 	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+	"github.com/gonebot-dev/goneplugin-status/threshold"
+	"github.com/gonebot-dev/goneplugin-status/timing"
 	"github.com/nfnt/resize"
-	"golang.org/x/image/font"
 )
 
 //go:embed assets
 var assetsFS embed.FS
 
 var bg image.Image
-var contentFont font.Face
-var titleFont font.Face
-
-// Constants
-const shadowOffsetX float64 = 10
-const shadowOffsetY float64 = 10
-const badgePaddingX float64 = 48
-const badgePaddingY float64 = 24
-const badgeMargin float64 = 32
-const panelPadding float64 = 48
-const panelMargin float64 = 48
-const canvasWidth float64 = 1280
 
 var golangBlue = "#007D9CC0"
 var success = "#67C23AC0"
 var warning = "#E6A23CC0"
 var danger = "#F56C6CC0"
 var shadow = "#00000070"
-var panel = "#FFFFFF9C"
+var panelColor = "#FFFFFF9C"
+var darkBadge = "#2222229C"
+
+// Sections of the full view
+const (
+	SectionHeader   = "header"
+	SectionActivity = "activity"
+	SectionPlugins  = "plugins"
+	SectionCPU      = "cpu"
+	SectionMemory   = "memory"
+	SectionDisk     = "disk"
+)
+
+// FullSections are the sections of the full view from top to bottom
+var FullSections = []string{SectionHeader, SectionActivity, SectionPlugins, SectionCPU, SectionMemory, SectionDisk}
+
+// Width of the canvas at scale 1
+const defaultWidth float64 = 1280
 
 func init() {
 	// Load background, asuming it to be 1280x...
@@ -48,588 +55,367 @@ func init() {
 	defer bgData.Close()
 	bg, _, _ = image.Decode(bgData)
 
-	// Load font
+	// Load font, the first one of the fallback chain
 	fontData, _ := assetsFS.ReadFile("assets/font.ttf")
-	f, _ := freetype.ParseFont(fontData)
-	titleFont = truetype.NewFace(f, &truetype.Options{
-		Size:    64,
-		DPI:     72,
-		Hinting: font.HintingFull,
-	})
-	contentFont = truetype.NewFace(f, &truetype.Options{
-		Size:    36,
-		DPI:     72,
-		Hinting: font.HintingFull,
-	})
+	AddFont(fontData)
+}
+
+// EmbeddedFont returns the font of the card, with the icons of the badges, e.g. for the dashboard
+func EmbeddedFont() []byte {
+	data, _ := assetsFS.ReadFile("assets/font.ttf")
+	return data
 }
 
-// Render renders the system info to an image and returns it as a base64 string
+// Options controls how the status image is rendered
+type Options struct {
+	// Locale of the labels, e.g. "en" or "zh-CN", empty for i18n.DefaultLocale
+	Locale string
+	// Output format and size budget
+	Encoding Encoding
+	// Scale of every size, fonts and shadows included, 1 when 0
+	Scale float64
+	// Width of the image in pixels, the layout reflows to it. 1280 times Scale when 0
+	Width float64
+	// Columns of the grid compact panels (e.g. disks) flow into, chosen from the width when 0
+	Columns int
+	// View to render, ViewFull when empty
+	View string
+	// Only render the panels of a section of the full view, e.g. SectionDisk, every one when empty
+	Section string
+	// Names of the silenced alert rules, shown as a muted badge
+	Muted []string
+}
+
+// size returns the scale and width with their defaults applied
+func (opts Options) size() (scale float64, width float64) {
+	scale, width = opts.Scale, opts.Width
+	if scale <= 0 {
+		scale = 1
+	}
+	if width <= 0 {
+		width = defaultWidth * scale
+	}
+	return
+}
+
+// Render renders the system info to an image with default options and returns it as a base64 string
 func Render() string {
-	// Render process
-	info := sysinfo.GetSysInfo()
-	tmp := gg.NewContext(0, 0)
-	tmp.SetFontFace(titleFont)
-	_, titleLineHeight := tmp.MeasureString("T")
-	tmp.SetFontFace(contentFont)
-	_, contentLineHeight := tmp.MeasureString("T")
-	canvasHeight := 0.0
-
-	//! Define panels
-	//* Panel for badges
-	canvasHeight += (panelMargin + panelPadding) * 2
-	//? Title badge
-	canvasHeight += badgePaddingY*3 + titleLineHeight
-	//? Adapter, Receive and Send badge
-	canvasHeight += badgePaddingY*2 + contentLineHeight + badgeMargin
-	//? Bot & System run time
-	canvasHeight += badgePaddingY*2 + contentLineHeight + badgeMargin
+	return RenderWith(Options{})
+}
 
-	//* Panel for CPU usage
-	canvasHeight += (panelPadding)*2 + panelMargin
-	//? CPU title badge
-	canvasHeight += badgePaddingY*2 + contentLineHeight
-	//? CPU Info badge
-	canvasHeight += badgePaddingY*2 + contentLineHeight + badgeMargin
-	//? CPU progress bar
-	canvasHeight += badgePaddingY + contentLineHeight + badgeMargin
+// RenderWith renders the system info to an image and returns it as a base64 string
+func RenderWith(opts Options) string {
+	return RenderInfo(sysinfo.GetSysInfo(), opts)
+}
 
-	//* Panel for memory usage
-	canvasHeight += (panelPadding)*2 + panelMargin
-	//? Memory title badge
-	canvasHeight += badgePaddingY*2 + contentLineHeight
-	//? Memory progress bar
-	canvasHeight += badgePaddingY + contentLineHeight + badgeMargin
-
-	//* Panel for every disk
-	for range info.Disks {
-		//? Disk progress bar
-		canvasHeight += (panelPadding)*2 + panelMargin + badgePaddingY*3 + contentLineHeight*2 + badgeMargin
+// RenderInfo renders a snapshot to an image and returns it as a base64 string, empty if it failed
+func RenderInfo(info sysinfo.SysInfo, opts Options) string {
+	encoded, err := RenderEncoded(info, opts)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Status: failed to render: %v", err))
+		return ""
+	}
+	if encoded.OverBudget {
+		slog.Warn(fmt.Sprintf("Status: the image is %d bytes at the lowest quality and scale, over the budget of %d", encoded.Size(), opts.Encoding.MaxBytes))
+	}
+	return "base64://" + base64.StdEncoding.EncodeToString(encoded.Data)
+}
+
+// RenderEncoded renders a snapshot and encodes it with opts.Encoding
+func RenderEncoded(info sysinfo.SysInfo, opts Options) (Encoded, error) {
+	return Encode(RenderImage(info, opts), opts.Encoding)
+}
+
+// RenderImage renders a snapshot to an image
+func RenderImage(info sysinfo.SysInfo, opts Options) image.Image {
+	// The labels are in the matched locale, names and other text may be in the requested one
+	ensureCJK(i18n.Match(opts.Locale))
+	ensureCJK(opts.Locale)
+	scale, width := opts.size()
+	d := newDrawer(scale)
+	if opts.View == ViewMini {
+		d.m = miniMetrics(d.m)
 	}
+	panels := panelsOf(info, opts)
 
-	//! Generate image
-	img := gg.NewContext(int(canvasWidth), int(canvasHeight))
-	img.SetFontFace(titleFont)
+	//! Layout
+	done := timing.Start(timing.Layout)
+	placements, height := d.layout(panels, width, opts.Columns)
+	done()
 
 	//! Render Process
-	//* Background
+	defer timing.Start(timing.Draw)()
+	d.dc = gg.NewContext(int(width), int(math.Ceil(height)))
+	drawBackground(d.dc)
+	for _, p := range placements {
+		p.draw(d, p.x, p.y, p.width, p.height)
+	}
+	return d.dc.Image()
+}
+
+// panelsOf returns the panels of the view and section of opts
+func panelsOf(info sysinfo.SysInfo, opts Options) (panels []panel) {
+	switch opts.View {
+	case ViewMini:
+		panels = miniPanels(info, opts.Locale)
+	case ViewActivity:
+		panels = leaderboardPanels(info, opts.Locale)
+	default:
+		panels = fullPanels(info, opts.Locale, opts.Muted)
+	}
+	if opts.Section == "" {
+		return
+	}
+	var kept []panel
+	for _, p := range panels {
+		if p.section == opts.Section {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// drawBackground covers the canvas with the background image, keeping it centered
+func drawBackground(dc *gg.Context) {
+	w, h := float64(dc.Width()), float64(dc.Height())
+	bw, bh := float64(bg.Bounds().Dx()), float64(bg.Bounds().Dy())
+	ratio := math.Max(w/bw, h/bh)
 	tmpBg := bg
-	if canvasHeight > canvasWidth {
-		tmpBg = resize.Resize(0, uint(canvasHeight), bg, resize.Lanczos3)
+	if ratio != 1 {
+		tmpBg = resize.Resize(uint(math.Ceil(bw*ratio)), uint(math.Ceil(bh*ratio)), bg, resize.Lanczos3)
 	}
-	img.DrawImageAnchored(tmpBg, int(canvasWidth/2), int(canvasHeight/2), 0.5, 0.5)
+	dc.DrawImageAnchored(tmpBg, int(w/2), int(h/2), 0.5, 0.5)
+}
 
-	//! Render Panels
-	//* Panel for badges
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		panelMargin+shadowOffsetX,
-		panelMargin+shadowOffsetY,
-		canvasWidth-panelMargin*2,
-		badgePaddingY*7+badgeMargin*2+contentLineHeight*2+titleLineHeight+panelPadding*2,
-		32,
-	)
-	img.Fill()
-	img.SetHexColor(panel)
-	img.DrawRoundedRectangle(
-		panelMargin,
-		panelMargin,
-		canvasWidth-panelMargin*2,
-		badgePaddingY*7+badgeMargin*2+contentLineHeight*2+titleLineHeight+panelPadding*2,
-		32,
-	)
-	img.Fill()
-	//? Title badge
-	logo := "\ue62a "
-	if runtime.GOOS == "macos" {
-		logo = "\uf179 "
-	} else if runtime.GOOS == "linux" {
-		logo = "\uf17c "
+// fullPanels lays out every section of the snapshot
+func fullPanels(info sysinfo.SysInfo, loc string, muted []string) (panels []panel) {
+	for _, section := range FullSections {
+		switch section {
+		case SectionHeader:
+			panels = append(panels, headerPanel(info, loc, muted))
+		case SectionActivity:
+			//* Panel for message traffic
+			if t := info.Throughput; t != nil {
+				panels = append(panels, activityPanel(*t, loc))
+			}
+		case SectionPlugins:
+			//* Panel for every plugin
+			for _, p := range info.Plugins {
+				panels = append(panels, pluginPanel(p, loc))
+			}
+		case SectionCPU:
+			panels = append(panels, cpuPanel(info, loc))
+		case SectionMemory:
+			panels = append(panels, memoryPanel(info, loc))
+		case SectionDisk:
+			panels = append(panels, diskPanels(info.Disks, loc)...)
+		}
 	}
-	str := fmt.Sprintf("%sGonebot on %s %s", logo, info.OS, info.Arch)
-	w, _ := img.MeasureString(str)
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+shadowOffsetX,
-		panelMargin+panelPadding+shadowOffsetY,
-		w+badgePaddingX*2,
-		titleLineHeight+badgePaddingY*3,
-		titleLineHeight/2.0+badgePaddingY*1.5,
-	)
-	img.Fill()
-	img.SetHexColor(golangBlue)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding,
-		panelMargin+panelPadding,
-		w+badgePaddingX*2,
-		titleLineHeight+badgePaddingY*3,
-		titleLineHeight/2.0+badgePaddingY*1.5,
-	)
-	img.Fill()
-	img.SetHexColor("#FFFFFF")
-	img.DrawString(
-		str,
-		panelMargin+panelPadding+badgePaddingX,
-		panelMargin+panelPadding+badgePaddingY*1.5+titleLineHeight,
-	)
-	//? Adapter, Receive and Send badge
-	img.SetFontFace(contentFont)
-	str = fmt.Sprintf("● %s", info.Backend)
-	w, _ = img.MeasureString(str)
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+shadowOffsetX,
-		panelMargin+panelPadding+badgePaddingY*3+titleLineHeight+badgeMargin+shadowOffsetY,
-		w+badgePaddingX*2,
-		contentLineHeight+badgePaddingY*2,
-		contentLineHeight/2.0+badgePaddingY,
-	)
-	img.Fill()
-	img.SetHexColor(success)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding,
-		panelMargin+panelPadding+badgePaddingY*3+titleLineHeight+badgeMargin,
-		w+badgePaddingX*2,
-		contentLineHeight+badgePaddingY*2,
-		contentLineHeight/2.0+badgePaddingY,
-	)
-	img.Fill()
-	img.SetHexColor("#FFFFFF")
-	img.DrawString(
-		str,
-		panelMargin+panelPadding+badgePaddingX,
-		panelMargin+panelPadding+badgePaddingY*4+badgeMargin+titleLineHeight+contentLineHeight,
-	)
-	lastW := w + badgePaddingX*2 + badgeMargin
-	str = fmt.Sprintf("● Recv: %d", info.ReceivedTotal)
-	w, _ = img.MeasureString(str)
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+lastW+shadowOffsetX,
-		panelMargin+panelPadding+badgePaddingY*3+titleLineHeight+badgeMargin+shadowOffsetY,
-		w+badgePaddingX*2,
-		contentLineHeight+badgePaddingY*2,
-		contentLineHeight/2.0+badgePaddingY,
-	)
-	img.Fill()
-	img.SetHexColor(warning)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+lastW,
-		panelMargin+panelPadding+badgePaddingY*3+titleLineHeight+badgeMargin,
-		w+badgePaddingX*2,
-		contentLineHeight+badgePaddingY*2,
-		contentLineHeight/2.0+badgePaddingY,
-	)
-	img.Fill()
-	img.SetHexColor("#FFFFFF")
-	img.DrawString(
-		str,
-		panelMargin+panelPadding+badgePaddingX+lastW,
-		panelMargin+panelPadding+badgePaddingY*4+badgeMargin+titleLineHeight+contentLineHeight,
-	)
-	lastW += w + badgePaddingX*2 + badgeMargin
-	str = fmt.Sprintf("● Sent: %d", info.SentTotal)
-	w, _ = img.MeasureString(str)
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+lastW+shadowOffsetX,
-		panelMargin+panelPadding+badgePaddingY*3+titleLineHeight+badgeMargin+shadowOffsetY,
-		w+badgePaddingX*2,
-		contentLineHeight+badgePaddingY*2,
-		contentLineHeight/2.0+badgePaddingY,
-	)
-	img.Fill()
-	img.SetHexColor(danger)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+lastW,
-		panelMargin+panelPadding+badgePaddingY*3+titleLineHeight+badgeMargin,
-		w+badgePaddingX*2,
-		contentLineHeight+badgePaddingY*2,
-		contentLineHeight/2.0+badgePaddingY,
-	)
-	img.Fill()
-	img.SetHexColor("#FFFFFF")
-	img.DrawString(
-		str,
-		panelMargin+panelPadding+badgePaddingX+lastW,
-		panelMargin+panelPadding+badgePaddingY*4+badgeMargin+titleLineHeight+contentLineHeight,
-	)
-	//? Bot & System run time
-	dayOrDays := "Day"
-	if info.Days > 1 {
-		dayOrDays = "Days"
+
+	//* Panels of the sections of other plugins
+	panels = append(panels, sectionPanels(info, loc)...)
+	return
+}
+
+// Title returns the title of the card, e.g. "Gonebot on Debian 12", without the logo
+func Title(info sysinfo.SysInfo, loc string) string {
+	d := distroOf(info.Platform, info.OS)
+	return i18n.T(loc, "title", strings.TrimSpace(d.Name+" "+info.PlatformVersion))
+}
+
+// headerPanel shows the title, the message counters and the run times
+func headerPanel(info sysinfo.SysInfo, loc string, muted []string) panel {
+	//* Panel for badges
+	header := panel{section: SectionHeader, elements: []element{
+		//? Title badge
+		badgeRow{title: true, badges: []badge{
+			{distroOf(info.Platform, info.OS).Logo + " " + Title(info, loc), golangBlue},
+		}},
+		//? Adapter, Receive and Send badge
+		badgeRow{badges: []badge{
+			{fmt.Sprintf("● %s", info.Backend), success},
+			{"● " + i18n.T(loc, "recv", i18n.Number(loc, float64(info.ReceivedTotal), 0)), warning},
+			{"● " + i18n.T(loc, "sent", i18n.Number(loc, float64(info.SentTotal), 0)), danger},
+		}},
+		//? Bot & System run time
+		splitRow{badges: []badge{
+			{i18n.T(loc, "uptime.sys", format.Duration(loc, time.Duration(info.Uptime)*time.Second)), darkBadge},
+			{i18n.T(loc, "uptime.bot", format.Duration(loc, time.Duration(info.BotUptime)*time.Second)), darkBadge},
+		}},
+	}}
+	//? Counters across restarts
+	if l := info.Lifetime; l != nil {
+		reason := darkBadge
+		if l.LastRestartReason == sysinfo.RestartCrash {
+			reason = danger
+		}
+		header.elements = append(header.elements, splitRow{badges: []badge{
+			{i18n.T(loc, "lifetime.recv", i18n.Number(loc, float64(l.Received), 0)), darkBadge},
+			{i18n.T(loc, "lifetime.sent", i18n.Number(loc, float64(l.Sent), 0)), darkBadge},
+			{i18n.T(loc, "lifetime.uptime", format.Duration(loc, time.Duration(l.Uptime)*time.Second)), darkBadge},
+		}}, splitRow{badges: []badge{
+			{i18n.N(loc, "lifetime.restarts", int64(l.Restarts), l.Restarts), darkBadge},
+			{i18n.T(loc, "lifetime.last", format.Duration(loc, time.Since(l.LastRestart)), i18n.T(loc, "restart."+l.LastRestartReason)), reason},
+		}})
 	}
-	str = fmt.Sprintf("Sys: %d %s %02d:%02d:%02d", info.Days, dayOrDays, info.Hours, info.Minutes, info.Seconds)
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+shadowOffsetX,
-		panelMargin+panelPadding+badgePaddingY*5+titleLineHeight+contentLineHeight+badgeMargin*2+shadowOffsetY,
-		(canvasWidth-badgeMargin-(panelPadding+panelMargin)*2)/2.0,
-		contentLineHeight+badgePaddingY*2,
-		contentLineHeight/2.0+badgePaddingY,
-	)
-	img.Fill()
-	img.SetHexColor("#2222229C")
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding,
-		panelMargin+panelPadding+badgePaddingY*5+titleLineHeight+contentLineHeight+badgeMargin*2,
-		(canvasWidth-badgeMargin-(panelPadding+panelMargin)*2)/2.0,
-		contentLineHeight+badgePaddingY*2,
-		contentLineHeight/2.0+badgePaddingY,
-	)
-	img.Fill()
-	img.SetHexColor("#FFFFFF")
-	img.DrawStringAnchored(
-		str,
-		panelMargin+panelPadding+(canvasWidth-badgeMargin-(panelPadding+panelMargin)*2)/4.0,
-		panelMargin+panelPadding+badgePaddingY*5+titleLineHeight+contentLineHeight*2+badgeMargin*2,
-		0.5, 0.5,
-	)
-	if info.BotDays > 1 {
-		dayOrDays = "Days"
+	//? Muted alerts badge
+	if len(muted) > 0 {
+		header.elements = append(header.elements, badgeRow{center: true, badges: []badge{
+			{"\uf1f6 " + i18n.T(loc, "muted", strings.Join(muted, ", ")), darkBadge},
+		}})
 	}
-	str = fmt.Sprintf("Bot: %d %s %02d:%02d:%02d", info.BotDays, dayOrDays, info.BotHours, info.BotMinutes, info.BotSeconds)
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+(canvasWidth-badgeMargin-(panelPadding+panelMargin)*2)/2.0+badgeMargin+shadowOffsetX,
-		panelMargin+panelPadding+badgePaddingY*5+titleLineHeight+contentLineHeight+badgeMargin*2+shadowOffsetY,
-		(canvasWidth-badgeMargin-(panelPadding+panelMargin)*2)/2.0,
-		contentLineHeight+badgePaddingY*2,
-		contentLineHeight/2.0+badgePaddingY,
-	)
-	img.Fill()
-	img.SetHexColor("#2222229C")
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+(canvasWidth-badgeMargin-(panelPadding+panelMargin)*2)/2.0+badgeMargin,
-		panelMargin+panelPadding+badgePaddingY*5+titleLineHeight+contentLineHeight+badgeMargin*2,
-		(canvasWidth-badgeMargin-(panelPadding+panelMargin)*2)/2.0,
-		contentLineHeight+badgePaddingY*2,
-		contentLineHeight/2.0+badgePaddingY,
-	)
-	img.Fill()
-	img.SetHexColor("#FFFFFF")
-	img.DrawStringAnchored(
-		str,
-		panelMargin+panelPadding+(canvasWidth-badgeMargin-(panelPadding+panelMargin)*2)*0.75+badgeMargin,
-		panelMargin+panelPadding+badgePaddingY*5+titleLineHeight+contentLineHeight*2+badgeMargin*2,
-		0.5, 0.5,
-	)
+	return header
+}
 
+// cpuPanel shows the CPU usage and load
+func cpuPanel(info sysinfo.SysInfo, loc string) panel {
 	//* Panel for CPU usage
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		panelMargin+shadowOffsetX,
-		panelMargin*2+panelPadding*2+badgePaddingY*7+titleLineHeight+contentLineHeight*2+badgeMargin*2+shadowOffsetY,
-		canvasWidth-panelMargin*2,
-		badgePaddingY*5+badgeMargin*2+contentLineHeight*3+panelPadding*2,
-		32,
-	)
-	img.Fill()
-	img.SetHexColor(panel)
-	img.DrawRoundedRectangle(
-		panelMargin,
-		panelMargin*2+panelPadding*2+badgePaddingY*7+titleLineHeight+contentLineHeight*2+badgeMargin*2,
-		canvasWidth-panelMargin*2,
-		badgePaddingY*5+badgeMargin*2+contentLineHeight*3+panelPadding*2,
-		32,
-	)
-	img.Fill()
-	//? CPU title badge
-	str = fmt.Sprintf("● CPU | Cores: %d", info.CpuCores)
-	w, _ = img.MeasureString(str)
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		(canvasWidth-(w+badgePaddingX*2))/2.0+shadowOffsetX,
-		panelMargin*2+panelPadding*3+badgePaddingY*7+titleLineHeight+contentLineHeight*2+badgeMargin*2+shadowOffsetY,
-		w+badgePaddingX*2,
-		badgePaddingY*2+contentLineHeight,
-		badgePaddingY+contentLineHeight/2.0,
-	)
-	img.Fill()
-	img.SetHexColor(danger)
-	img.DrawRoundedRectangle(
-		(canvasWidth-(w+badgePaddingX*2))/2.0,
-		panelMargin*2+panelPadding*3+badgePaddingY*7+titleLineHeight+contentLineHeight*2+badgeMargin*2,
-		w+badgePaddingX*2,
-		badgePaddingY*2+contentLineHeight,
-		badgePaddingY+contentLineHeight/2.0,
-	)
-	img.Fill()
-	img.SetHexColor("#FFFFFF")
-	img.DrawString(
-		str,
-		(canvasWidth-(w+badgePaddingX*2))/2.0+badgePaddingX,
-		panelMargin*2+panelPadding*3+badgePaddingY*8+titleLineHeight+contentLineHeight*3+badgeMargin*2,
-	)
+	cpu := panel{section: SectionCPU, elements: []element{
+		//? CPU title badge
+		badgeRow{center: true, badges: []badge{
+			{"● " + i18n.N(loc, "cpu", int64(info.CpuCores), info.Arch, info.CpuCores), danger},
+		}},
+	}}
 	//? CPU Info badge
-	str = fmt.Sprint(info.CpuInfo)
-	w, _ = img.MeasureString(str)
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		(canvasWidth-(w+badgePaddingX*2))/2.0+shadowOffsetX,
-		panelMargin*2+panelPadding*3+badgePaddingY*9+titleLineHeight+contentLineHeight*3+badgeMargin*3+shadowOffsetY,
-		w+badgePaddingX*2,
-		badgePaddingY*2+contentLineHeight,
-		badgePaddingY+contentLineHeight/2.0,
-	)
-	img.Fill()
-	img.SetHexColor(golangBlue)
-	img.DrawRoundedRectangle(
-		(canvasWidth-(w+badgePaddingX*2))/2.0,
-		panelMargin*2+panelPadding*3+badgePaddingY*9+titleLineHeight+contentLineHeight*3+badgeMargin*3,
-		w+badgePaddingX*2,
-		badgePaddingY*2+contentLineHeight,
-		badgePaddingY+contentLineHeight/2.0,
-	)
-	img.Fill()
-	img.SetHexColor("#FFFFFF")
-	img.DrawString(
-		str,
-		(canvasWidth-(w+badgePaddingX*2))/2.0+badgePaddingX,
-		panelMargin*2+panelPadding*3+badgePaddingY*10+titleLineHeight+contentLineHeight*4+badgeMargin*3,
-	)
+	if info.CpuInfo != "" {
+		cpu.elements = append(cpu.elements, badgeRow{center: true, badges: []badge{{info.CpuInfo, golangBlue}}})
+	}
 	//? CPU progress bar
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+contentLineHeight*5+shadowOffsetX,
-		panelMargin*2+panelPadding*3+badgePaddingY*11+titleLineHeight+contentLineHeight*4+badgeMargin*4+shadowOffsetY,
-		canvasWidth-panelMargin*2-panelPadding*2-contentLineHeight*6,
-		badgePaddingY+contentLineHeight,
-		(badgePaddingY+contentLineHeight)/2.0,
-	)
-	img.Fill()
-	img.SetHexColor(panel)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+contentLineHeight*5,
-		panelMargin*2+panelPadding*3+badgePaddingY*11+titleLineHeight+contentLineHeight*4+badgeMargin*4,
-		canvasWidth-panelMargin*2-panelPadding*2-contentLineHeight*6,
-		badgePaddingY+contentLineHeight,
-		(badgePaddingY+contentLineHeight)/2.0,
-	)
-	img.Fill()
-	if info.CpuUsedPercent < 40 {
-		img.SetHexColor(success)
-	} else if info.CpuUsedPercent < 80 {
-		img.SetHexColor(warning)
-	} else {
-		img.SetHexColor(danger)
+	cpu.elements = append(cpu.elements, barRow{
+		label:   format.Percent(loc, info.CpuUsedPercent),
+		caption: i18n.T(loc, "cpu.load", i18n.Number(loc, info.CpuLoad1, 2), i18n.Number(loc, info.CpuLoad5, 2), i18n.Number(loc, info.CpuLoad15, 2)),
+		percent: info.CpuUsedPercent,
+		color:   threshold.For(threshold.CPU).Level(info.CpuUsedPercent).Color,
+	})
+	//? CPU usage of the recent samples
+	if recent := sysinfo.History(); len(recent) > 1 {
+		values := make([]float64, len(recent))
+		for i, s := range recent {
+			values[i] = s.CpuUsedPercent
+		}
+		cpu.elements = append(cpu.elements, sparkline{values: values, lo: 0, hi: 100, color: danger})
 	}
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+contentLineHeight*5,
-		panelMargin*2+panelPadding*3+badgePaddingY*11+titleLineHeight+contentLineHeight*4+badgeMargin*4,
-		(canvasWidth-panelMargin*2-panelPadding*2-contentLineHeight*6)*info.CpuUsedPercent/100.0,
-		badgePaddingY+contentLineHeight,
-		(badgePaddingY+contentLineHeight)/2.0,
-	)
-	img.Fill()
-	img.SetHexColor("#000000")
-	img.DrawString(
-		fmt.Sprintf("%5.2f%%", info.CpuUsedPercent),
-		panelMargin+panelPadding,
-		panelMargin*2+panelPadding*3+badgePaddingY*11.5+titleLineHeight+contentLineHeight*5+badgeMargin*4,
-	)
-	img.SetHexColor("#000000")
-	img.DrawStringAnchored(
-		fmt.Sprintf("Load: %.2f / %.2f / %.2f", info.CpuLoad1, info.CpuLoad5, info.CpuLoad15),
-		(canvasWidth+contentLineHeight*5)/2.0,
-		panelMargin*2+panelPadding*3+badgePaddingY*11.5+titleLineHeight+contentLineHeight*4.5+badgeMargin*4,
-		0.5, 0.5,
-	)
+	return cpu
+}
 
+// memoryPanel shows the memory usage
+func memoryPanel(info sysinfo.SysInfo, loc string) panel {
 	//* Panel for memory usage
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		panelMargin+shadowOffsetX,
-		panelMargin*3+panelPadding*4+badgePaddingY*12+titleLineHeight+contentLineHeight*5+badgeMargin*4+shadowOffsetY,
-		canvasWidth-panelMargin*2,
-		badgePaddingY*3+badgeMargin*1+contentLineHeight*2+panelPadding*2,
-		32,
-	)
-	img.Fill()
-	img.SetHexColor(panel)
-	img.DrawRoundedRectangle(
-		panelMargin,
-		panelMargin*3+panelPadding*4+badgePaddingY*12+titleLineHeight+contentLineHeight*5+badgeMargin*4,
-		canvasWidth-panelMargin*2,
-		badgePaddingY*3+badgeMargin*1+contentLineHeight*2+panelPadding*2,
-		32,
-	)
-	img.Fill()
-	//? Memory title badge
-	str = fmt.Sprintf("● Memory | Total: %.2f GB", float64(info.MemAll)/1024.0)
-	w, _ = img.MeasureString(str)
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		(canvasWidth-(w+badgePaddingX*2))/2.0+shadowOffsetX,
-		panelMargin*3+panelPadding*5+badgePaddingY*12+titleLineHeight+contentLineHeight*5+badgeMargin*4+shadowOffsetY,
-		w+badgePaddingX*2,
-		badgePaddingY*2+contentLineHeight,
-		badgePaddingY+contentLineHeight/2.0,
-	)
-	img.Fill()
-	img.SetHexColor(warning)
-	img.DrawRoundedRectangle(
-		(canvasWidth-(w+badgePaddingX*2))/2.0,
-		panelMargin*3+panelPadding*5+badgePaddingY*12+titleLineHeight+contentLineHeight*5+badgeMargin*4,
-		w+badgePaddingX*2,
-		badgePaddingY*2+contentLineHeight,
-		badgePaddingY+contentLineHeight/2.0,
-	)
-	img.Fill()
-	img.SetHexColor("#FFFFFF")
-	img.DrawString(
-		str,
-		(canvasWidth-(w+badgePaddingX*2))/2.0+badgePaddingX,
-		panelMargin*3+panelPadding*5+badgePaddingY*13+titleLineHeight+contentLineHeight*6+badgeMargin*4,
-	)
-	//? Memory progress bar
-	img.SetHexColor(shadow)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+contentLineHeight*5+shadowOffsetX,
-		panelMargin*3+panelPadding*5+badgePaddingY*14+titleLineHeight+contentLineHeight*6+badgeMargin*5+shadowOffsetY,
-		canvasWidth-panelMargin*2-panelPadding*2-contentLineHeight*6,
-		badgePaddingY+contentLineHeight,
-		(badgePaddingY+contentLineHeight)/2.0,
-	)
-	img.Fill()
-	img.SetHexColor(panel)
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+contentLineHeight*5,
-		panelMargin*3+panelPadding*5+badgePaddingY*14+titleLineHeight+contentLineHeight*6+badgeMargin*5,
-		canvasWidth-panelMargin*2-panelPadding*2-contentLineHeight*6,
-		badgePaddingY+contentLineHeight,
-		(badgePaddingY+contentLineHeight)/2.0,
-	)
-	img.Fill()
-	if info.MemUsedPercent < 40 {
-		img.SetHexColor(success)
-	} else if info.MemUsedPercent < 80 {
-		img.SetHexColor(warning)
-	} else {
-		img.SetHexColor(danger)
+	return panel{section: SectionMemory, elements: []element{
+		//? Memory title badge
+		badgeRow{center: true, badges: []badge{
+			{"● " + i18n.T(loc, "memory", format.Bytes(loc, info.MemAll)), warning},
+		}},
+		//? Memory progress bar
+		barRow{
+			label:   format.Percent(loc, info.MemUsedPercent),
+			caption: i18n.T(loc, "usage", format.Bytes(loc, info.MemUsed), format.Bytes(loc, info.MemAll)),
+			percent: info.MemUsedPercent,
+			color:   threshold.For(threshold.Memory).Level(info.MemUsedPercent).Color,
+		},
+	}}
+}
+
+// diskPanels shows the usage of every disk, after their shares when there are several
+func diskPanels(disks []sysinfo.DiskInfo, loc string) (panels []panel) {
+	//* Panel sharing the space of every disk between them
+	if len(disks) > 1 {
+		panels = append(panels, disksPanel(disks, loc))
+	}
+
+	//* Panel for every disk, the total is already in the caption
+	for _, disk := range disks {
+		str := "● " + i18n.T(loc, "disk", disk.Name)
+		if disk.Name == "" {
+			str = "● " + i18n.T(loc, "disk.unnamed")
+		}
+		panels = append(panels, panel{section: SectionDisk, compact: true, elements: []element{
+			//? Disk badge
+			badgeRow{center: true, badges: []badge{{str, success}}},
+			//? Disk progress bar
+			barRow{
+				label:   format.Percent(loc, disk.UsedPercent),
+				caption: i18n.T(loc, "usage", format.Bytes(loc, disk.Used), format.Bytes(loc, disk.Total)),
+				percent: disk.UsedPercent,
+				color:   threshold.ForMount(disk.Mount()).Level(disk.UsedPercent).Color,
+			},
+		}})
 	}
-	img.DrawRoundedRectangle(
-		panelMargin+panelPadding+contentLineHeight*5,
-		panelMargin*3+panelPadding*5+badgePaddingY*14+titleLineHeight+contentLineHeight*6+badgeMargin*5,
-		(canvasWidth-panelMargin*2-panelPadding*2-contentLineHeight*6)*info.MemUsedPercent/100.0,
-		badgePaddingY+contentLineHeight,
-		(badgePaddingY+contentLineHeight)/2.0,
-	)
-	img.Fill()
-	img.SetHexColor("#000000")
-	img.DrawString(
-		fmt.Sprintf("%5.2f%%", info.MemUsedPercent),
-		panelMargin+panelPadding,
-		panelMargin*3+panelPadding*5+badgePaddingY*14.5+titleLineHeight+contentLineHeight*7+badgeMargin*5,
-	)
-	img.SetHexColor("#000000")
-	img.DrawStringAnchored(
-		fmt.Sprintf("%.2f GB / %.2f GB", float64(info.MemUsed)/1024.0, float64(info.MemAll)/1024.0),
-		(canvasWidth+contentLineHeight*5)/2.0,
-		panelMargin*3+panelPadding*5+badgePaddingY*14.5+titleLineHeight+contentLineHeight*6.5+badgeMargin*5,
-		0.5, 0.5,
-	)
-
-	//* Panel for every disk
-	for i := range info.Disks {
-		index := float64(i)
-		img.SetHexColor(shadow)
-		img.DrawRoundedRectangle(
-			panelMargin+shadowOffsetX,
-			panelMargin*(4+index)+panelPadding*(6+2*index)+badgePaddingY*(15+3*index)+titleLineHeight+contentLineHeight*(7+2*index)+badgeMargin*(5+index)+shadowOffsetY,
-			canvasWidth-panelMargin*2,
-			badgePaddingY*3+badgeMargin*1+contentLineHeight*2+panelPadding*2,
-			32,
-		)
-		img.Fill()
-		img.SetHexColor(panel)
-		img.DrawRoundedRectangle(
-			panelMargin,
-			panelMargin*(4+index)+panelPadding*(6+2*index)+badgePaddingY*(15+3*index)+titleLineHeight+contentLineHeight*(7+2*index)+badgeMargin*(5+index),
-			canvasWidth-panelMargin*2,
-			badgePaddingY*3+badgeMargin*1+contentLineHeight*2+panelPadding*2,
-			32,
-		)
-		img.Fill()
-		//? Disk badge
-		str = fmt.Sprintf("● Disk: \"%s\" | Total: %.2f GB", info.Disks[i].Name, float64(info.Disks[i].Total)/1024.0)
-		w, _ = img.MeasureString(str)
-		img.SetHexColor(shadow)
-		img.DrawRoundedRectangle(
-			(canvasWidth-(w+badgePaddingX*2))/2.0+shadowOffsetX,
-			panelMargin*(4+index)+panelPadding*(7+2*index)+badgePaddingY*(15+3*index)+titleLineHeight+contentLineHeight*(7+2*index)+badgeMargin*(5+index)+shadowOffsetY,
-			w+badgePaddingX*2,
-			badgePaddingY*2+contentLineHeight,
-			badgePaddingY+contentLineHeight/2.0,
-		)
-		img.Fill()
-		img.SetHexColor(success)
-		img.DrawRoundedRectangle(
-			(canvasWidth-(w+badgePaddingX*2))/2.0,
-			panelMargin*(4+index)+panelPadding*(7+2*index)+badgePaddingY*(15+3*index)+titleLineHeight+contentLineHeight*(7+2*index)+badgeMargin*(5+index),
-			w+badgePaddingX*2,
-			badgePaddingY*2+contentLineHeight,
-			badgePaddingY+contentLineHeight/2.0,
-		)
-		img.Fill()
-		img.SetHexColor("#FFFFFF")
-		img.DrawString(
-			str,
-			(canvasWidth-(w+badgePaddingX*2))/2.0+badgePaddingX,
-			panelMargin*(4+index)+panelPadding*(7+2*index)+badgePaddingY*(16+3*index)+titleLineHeight+contentLineHeight*(8+2*index)+badgeMargin*(5+index),
-		)
-		//? Disk progress bar
-		img.SetHexColor(shadow)
-		img.DrawRoundedRectangle(
-			panelMargin+panelPadding+contentLineHeight*5+shadowOffsetX,
-			panelMargin*(4+index)+panelPadding*(7+2*index)+badgePaddingY*(17+3*index)+titleLineHeight+contentLineHeight*(8+2*index)+badgeMargin*(6+index)+shadowOffsetY,
-			canvasWidth-panelMargin*2-panelPadding*2-contentLineHeight*6,
-			badgePaddingY+contentLineHeight,
-			(badgePaddingY+contentLineHeight)/2.0,
-		)
-		img.Fill()
-		img.SetHexColor(panel)
-		img.DrawRoundedRectangle(
-			panelMargin+panelPadding+contentLineHeight*5,
-			panelMargin*(4+index)+panelPadding*(7+2*index)+badgePaddingY*(17+3*index)+titleLineHeight+contentLineHeight*(8+2*index)+badgeMargin*(6+index),
-			canvasWidth-panelMargin*2-panelPadding*2-contentLineHeight*6,
-			badgePaddingY+contentLineHeight,
-			(badgePaddingY+contentLineHeight)/2.0,
-		)
-		img.Fill()
-		if info.Disks[i].UsedPercent < 40 {
-			img.SetHexColor(success)
-		} else if info.Disks[i].UsedPercent < 80 {
-			img.SetHexColor(warning)
-		} else {
-			img.SetHexColor(danger)
+	return
+}
+
+// disksPanel shows the used space of every disk as a part of the space of them all
+func disksPanel(disks []sysinfo.DiskInfo, loc string) panel {
+	var legend []badge
+	var slices []Slice
+	var used, total uint64
+	for i, disk := range disks {
+		name := disk.Name
+		if name == "" {
+			name = i18n.T(loc, "disk.unnamed")
 		}
-		img.DrawRoundedRectangle(
-			panelMargin+panelPadding+contentLineHeight*5,
-			panelMargin*(4+index)+panelPadding*(7+2*index)+badgePaddingY*(17+3*index)+titleLineHeight+contentLineHeight*(8+2*index)+badgeMargin*(6+index),
-			(canvasWidth-panelMargin*2-panelPadding*2-contentLineHeight*6)*info.Disks[i].UsedPercent/100.0,
-			badgePaddingY+contentLineHeight,
-			(badgePaddingY+contentLineHeight)/2.0,
-		)
-		img.Fill()
-		img.SetHexColor("#000000")
-		img.DrawString(
-			fmt.Sprintf("%5.2f%%", info.Disks[i].UsedPercent),
-			panelMargin+panelPadding,
-			panelMargin*(4+index)+panelPadding*(7+2*index)+badgePaddingY*(17.5+3*index)+titleLineHeight+contentLineHeight*(9+2*index)+badgeMargin*(6+index),
-		)
-		img.SetHexColor("#000000")
-		img.DrawStringAnchored(
-			fmt.Sprintf("%.2f GB / %.2f GB", float64(info.Disks[i].Used)/1024.0, float64(info.Disks[i].Total)/1024.0),
-			(canvasWidth+contentLineHeight*5)/2.0,
-			panelMargin*(4+index)+panelPadding*(7+2*index)+badgePaddingY*(17.5+3*index)+titleLineHeight+contentLineHeight*(8.5+2*index)+badgeMargin*(6+index),
-			0.5, 0.5,
-		)
+		color := DefaultTheme.color(i, "")
+		legend = append(legend, badge{"● " + name, color})
+		slices = append(slices, Slice{Value: float64(disk.Used), Color: color})
+		used, total = used+disk.Used, total+disk.Total
 	}
+	slices = append(slices, Slice{Value: float64(total - min(used, total)), Color: DefaultTheme.Track})
+	return panel{section: SectionDisk, elements: []element{
+		//? Disk names in the colors of their slices
+		badgeRow{center: true, badges: legend},
+		//? Used space by disk
+		stackedBar{slices: slices, caption: i18n.T(loc, "usage", format.Bytes(loc, used), format.Bytes(loc, total))},
+	}}
+}
 
-	//! Calculate result
-	var result bytes.Buffer
-	writer := io.Writer(&result)
-	img.EncodePNG(writer)
+// activityPanel shows the message rates, counts and traffic of the last 24 hours
+func activityPanel(t sysinfo.Throughput, loc string) panel {
+	values := make([]float64, len(t.Hourly))
+	labels := make([]string, len(t.Hourly))
+	for i, n := range t.Hourly {
+		values[i] = float64(n)
+		if hour := t.HourlyFrom.Add(time.Duration(i) * time.Hour).Hour(); hour%6 == 0 {
+			labels[i] = fmt.Sprintf("%02d:00", hour)
+		}
+	}
+	return panel{section: SectionActivity, elements: []element{
+		//? Activity title badge
+		badgeRow{center: true, badges: []badge{
+			{"● " + i18n.T(loc, "activity"), golangBlue},
+		}},
+		//? Rates
+		splitRow{badges: []badge{
+			{i18n.T(loc, "activity.rate", i18n.Number(loc, t.PerMinute, 1)), darkBadge},
+			{i18n.T(loc, "activity.peak", i18n.Number(loc, t.PeakPerMinute, 1)), darkBadge},
+		}},
+		//? Counts
+		splitRow{badges: []badge{
+			{i18n.T(loc, "activity.today", i18n.Number(loc, float64(t.Today), 0)), darkBadge},
+			{i18n.T(loc, "activity.week", i18n.Number(loc, float64(t.Week), 0)), darkBadge},
+		}},
+		//? Messages by hour
+		histogram{values: values, labels: labels, color: golangBlue},
+	}}
+}
 
-	return "base64://" + base64.StdEncoding.EncodeToString(result.Bytes())
+// pluginPanel shows a plugin and how its handlers did
+func pluginPanel(p sysinfo.PluginStats, loc string) panel {
+	elements := []element{
+		//? Name and version badge
+		badgeRow{center: true, badges: []badge{{"● " + strings.TrimSpace(p.Name+" "+p.Version), golangBlue}}},
+	}
+	//? Description badge
+	if p.Description != "" {
+		elements = append(elements, badgeRow{center: true, badges: []badge{{p.Description, darkBadge}}})
+	}
+	//? Handler calls, average latency and panics
+	stats := []badge{{"\uf0e7 " + i18n.Number(loc, float64(p.Invocations), 0), darkBadge}}
+	if p.Invocations > 0 {
+		stats = append(stats, badge{"\uf017 " + format.Latency(loc, p.AvgLatency()), darkBadge})
+	}
+	if p.Errors > 0 {
+		stats = append(stats, badge{"\uf071 " + i18n.Number(loc, float64(p.Errors), 0), danger})
+	}
+	elements = append(elements, badgeRow{center: true, badges: stats})
+	return panel{section: SectionPlugins, compact: true, elements: elements}
 }
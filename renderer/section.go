@@ -0,0 +1,88 @@
+package renderer
+
+import (
+	"sync"
+
+	"github.com/fogleman/gg"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+)
+
+// SectionContext is what a registered section draws from
+type SectionContext struct {
+	Info   sysinfo.SysInfo
+	Locale string
+	// Scale of the render, sizes are multiplied by it
+	Scale float64
+	// Theme to draw the primitives with, e.g. RingGauge or Sparkline
+	Theme Theme
+}
+
+// Section is a panel of the full view drawn by another plugin, see RegisterSection
+type Section struct {
+	// Compact sections flow into the grid like the disks
+	Compact bool
+	// Height returns the height of the content for a width
+	Height func(c SectionContext, width float64) float64
+	// Draw draws the content with its top left corner at (x, y), inside the panel
+	Draw func(dc *gg.Context, c SectionContext, x, y, width float64)
+}
+
+type namedSection struct {
+	name string
+	Section
+}
+
+// Registered sections, in the order they were registered
+var sections []namedSection
+var sectionsLock sync.RWMutex
+
+// RegisterSection adds a section after the built-in ones of the full view, or replaces the one of the same name.
+// The name is what Options.Section selects
+func RegisterSection(name string, section Section) {
+	sectionsLock.Lock()
+	defer sectionsLock.Unlock()
+	for i := range sections {
+		if sections[i].name == name {
+			sections[i].Section = section
+			return
+		}
+	}
+	sections = append(sections, namedSection{name, section})
+}
+
+// sectionPanels returns the panels of the registered sections
+func sectionPanels(info sysinfo.SysInfo, loc string) (panels []panel) {
+	sectionsLock.RLock()
+	defer sectionsLock.RUnlock()
+	for _, s := range sections {
+		if s.Height == nil || s.Draw == nil {
+			continue
+		}
+		panels = append(panels, panel{section: s.name, compact: s.Compact, elements: []element{
+			customElement{s.name, s.Section, info, loc},
+		}})
+	}
+	return
+}
+
+// customElement draws a registered section
+type customElement struct {
+	name    string
+	section Section
+	info    sysinfo.SysInfo
+	locale  string
+}
+
+func (c customElement) context(d *drawer) SectionContext {
+	return SectionContext{Info: c.info, Locale: c.locale, Scale: d.m.scale, Theme: d.theme()}
+}
+
+func (c customElement) height(d *drawer, width float64) float64 {
+	return c.section.Height(c.context(d), width)
+}
+
+func (c customElement) draw(d *drawer, x, y, width float64) {
+	d.dc.Push()
+	defer d.dc.Pop()
+	c.section.Draw(d.dc, c.context(d), x, y, width)
+}
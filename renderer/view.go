@@ -0,0 +1,114 @@
+package renderer
+
+import (
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+)
+
+// PanelView is a panel of the card as data, for pages laying the card out themselves like the dashboard
+type PanelView struct {
+	// Section of the full view, see FullSections, empty in the other views
+	Section  string        `json:"section,omitempty"`
+	Compact  bool          `json:"compact"`
+	Elements []ElementView `json:"elements"`
+}
+
+// ElementView is an element of a panel, Kind tells which fields are set:
+//   - "badges": Badges flowing left to right, Title and Center
+//   - "split": Badges sharing the width
+//   - "bar": a progress bar with Label, Caption, Percent and Color
+//   - "gauge": a ring gauge with Label, Value, Percent and Color
+//   - "stackedBar": Slices and Caption
+//   - "sparkline": Values between Lo and Hi, and Color
+//   - "histogram": Values, Labels and Color
+//   - "row": Elements side by side, sharing the width by Weights
+//   - "section": a section registered by another plugin, only drawn on the card
+type ElementView struct {
+	Kind     string        `json:"kind"`
+	Badges   []BadgeView   `json:"badges,omitempty"`
+	Title    bool          `json:"title,omitempty"`
+	Center   bool          `json:"center,omitempty"`
+	Label    string        `json:"label,omitempty"`
+	Value    string        `json:"value,omitempty"`
+	Caption  string        `json:"caption,omitempty"`
+	Percent  float64       `json:"percent,omitempty"`
+	Color    string        `json:"color,omitempty"`
+	Slices   []Slice       `json:"slices,omitempty"`
+	Values   []float64     `json:"values,omitempty"`
+	Labels   []string      `json:"labels,omitempty"`
+	Lo       float64       `json:"lo,omitempty"`
+	Hi       float64       `json:"hi,omitempty"`
+	Elements []ElementView `json:"elements,omitempty"`
+	Weights  []float64     `json:"weights,omitempty"`
+}
+
+// BadgeView is a badge with white text
+type BadgeView struct {
+	Text  string `json:"text"`
+	Color string `json:"color"`
+}
+
+// Panels returns the panels RenderImage draws for a snapshot, as data
+func Panels(info sysinfo.SysInfo, opts Options) []PanelView {
+	var views []PanelView
+	for _, p := range panelsOf(info, opts) {
+		v := PanelView{Section: p.section, Compact: p.compact}
+		for _, e := range p.elements {
+			v.Elements = append(v.Elements, e.view())
+		}
+		views = append(views, v)
+	}
+	return views
+}
+
+func badgeViews(badges []badge) (views []BadgeView) {
+	for _, b := range badges {
+		views = append(views, BadgeView{b.text, b.color})
+	}
+	return
+}
+
+func (b badgeRow) view() ElementView {
+	return ElementView{Kind: "badges", Badges: badgeViews(b.badges), Title: b.title, Center: b.center}
+}
+
+func (s splitRow) view() ElementView {
+	return ElementView{Kind: "split", Badges: badgeViews(s.badges)}
+}
+
+func (b barRow) view() ElementView {
+	return ElementView{Kind: "bar", Label: b.label, Caption: b.caption, Percent: b.percent, Color: b.color}
+}
+
+func (g gauge) view() ElementView {
+	return ElementView{Kind: "gauge", Label: g.label, Value: g.value, Percent: g.percent, Color: g.color}
+}
+
+func (s stackedBar) view() ElementView {
+	// Slices get their colors from the theme, as the page has none
+	slices := make([]Slice, len(s.slices))
+	for i, slice := range s.slices {
+		slices[i] = Slice{Value: slice.Value, Color: DefaultTheme.color(i, slice.Color)}
+	}
+	return ElementView{Kind: "stackedBar", Slices: slices, Caption: s.caption}
+}
+
+func (s sparkline) view() ElementView {
+	return ElementView{Kind: "sparkline", Values: s.values, Lo: s.lo, Hi: s.hi, Color: s.color}
+}
+
+func (h histogram) view() ElementView {
+	return ElementView{Kind: "histogram", Values: h.values, Labels: h.labels, Color: h.color}
+}
+
+func (r row) view() ElementView {
+	v := ElementView{Kind: "row"}
+	for i, e := range r.elements {
+		v.Elements = append(v.Elements, e.view())
+		v.Weights = append(v.Weights, r.weight(i))
+	}
+	return v
+}
+
+func (c customElement) view() ElementView {
+	return ElementView{Kind: "section", Label: c.name}
+}
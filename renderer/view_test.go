@@ -0,0 +1,48 @@
+package renderer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+	"github.com/gonebot-dev/goneplugin-status/threshold"
+)
+
+func TestPanels(t *testing.T) {
+	threshold.SetMount("/data", threshold.Thresholds{{Name: "ok", Min: 0, Color: "#000000FF"}})
+	t.Cleanup(func() { threshold.SetMount("/data", threshold.For(threshold.Disk)) })
+	info := card().Redact(sysinfo.Redaction{MountPaths: sysinfo.Mask})
+
+	panels := Panels(info, Options{})
+	// Sections come in the order of the card
+	last := -1
+	for _, p := range panels {
+		i := slices.Index(FullSections, p.Section)
+		if i < last {
+			t.Errorf("got section %s after %s", p.Section, FullSections[last])
+		}
+		last = max(last, i)
+	}
+
+	// Disk bars are colored by the thresholds of the real mount, not the masked name
+	var colors []string
+	for _, p := range panels {
+		for _, e := range p.Elements {
+			if p.Section == SectionDisk && e.Kind == "bar" {
+				colors = append(colors, e.Color)
+			}
+		}
+	}
+	want := []string{threshold.ForMount("/").Level(58.6).Color, "#000000FF"}
+	if !slices.Equal(colors, want) {
+		t.Errorf("got disk colors %v, want %v", colors, want)
+	}
+
+	for _, section := range []string{SectionCPU, SectionDisk} {
+		for _, p := range Panels(info, Options{Section: section}) {
+			if p.Section != section {
+				t.Errorf("Section %s: got a panel of %s", section, p.Section)
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package status
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/format"
+	"github.com/gonebot-dev/goneplugin-status/history"
+	"github.com/gonebot-dev/goneplugin-status/i18n"
+	"github.com/gonebot-dev/goneplugin-status/renderer"
+	"github.com/gonebot-dev/goneplugin-status/schedule"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+)
+
+// ViewSummary is a text report with the min/avg/max of the metrics since the previous report,
+// or only since the last restart without EnableHistory
+const ViewSummary = "summary"
+
+// ReportTarget is a user or a group a report is posted to
+type ReportTarget struct {
+	UserID  string
+	GroupID string
+	// View posted to this target, the report's when empty
+	View string
+}
+
+// Report posts the status card or a summary on a schedule
+type Report struct {
+	// Name of the report, unique, it keys the last run kept across restarts
+	Name string
+	// Cron expression, e.g. "0 9 * * mon-fri" or "@daily", see schedule.Parse
+	Schedule string
+	// Timezone of the schedule, e.g. "Asia/Shanghai", local time when empty
+	Timezone string
+	// View posted, renderer.ViewFull, renderer.ViewMini or ViewSummary. ViewFull when empty
+	View    string
+	Targets []ReportTarget
+}
+
+// Reports posted by the bot, see schedule.CatchUp for the runs missed while it was down
+var Reports = []Report{}
+
+// Summaries since the previous run of every report
+var summaries = map[string]*sysinfo.Summary{}
+var summariesLock sync.Mutex
+
+func startReports() {
+	if len(Reports) == 0 {
+		return
+	}
+	for _, report := range Reports {
+		cron, err := schedule.Parse(report.Schedule)
+		if err != nil {
+			slog.Error(fmt.Sprintf("Status: report %s: %v", report.Name, err))
+			continue
+		}
+		var loc *time.Location
+		if report.Timezone != "" {
+			if loc, err = time.LoadLocation(report.Timezone); err != nil {
+				slog.Error(fmt.Sprintf("Status: report %s: %v", report.Name, err))
+				continue
+			}
+		}
+		summariesLock.Lock()
+		summaries[report.Name] = &sysinfo.Summary{}
+		summariesLock.Unlock()
+		schedule.Start(schedule.Job{
+			Name:     "report:" + report.Name,
+			Cron:     cron,
+			Location: loc,
+			Run:      func(at time.Time, previous time.Time) { postReport(report, at, previous) },
+		})
+	}
+
+	sysinfo.StartSampler()
+	samples, _ := sysinfo.Subscribe()
+	go func() {
+		for s := range samples {
+			summariesLock.Lock()
+			for _, summary := range summaries {
+				summary.Add(s)
+			}
+			summariesLock.Unlock()
+		}
+	}()
+}
+
+// postReport queues the report for every target and starts a new summary
+func postReport(report Report, at time.Time, previous time.Time) {
+	summariesLock.Lock()
+	summary := *summaries[report.Name]
+	summaries[report.Name] = &sysinfo.Summary{}
+	summariesLock.Unlock()
+	// The history also covers the time before the last restart
+	if history.Enabled() {
+		if previous.IsZero() {
+			previous = at.Add(-24 * time.Hour)
+		}
+		summary = history.Summarize(previous, at)
+	}
+
+	info, _ := sysinfo.Latest()
+	for _, t := range report.Targets {
+		target := targetOf(t.UserID, t.GroupID)
+		msg := replyTo(target)
+		view := t.View
+		if view == "" {
+			view = report.View
+		}
+		// The text names the disks like the card does
+		redaction := redactionOf(target)
+		snapshot := info.Redact(redaction)
+		if view == ViewSummary {
+			msg.AddTextSegment(summaryText(summary, localeOf(target), snapshot, redaction))
+		} else {
+			opts := renderer.Options{
+				View:     view,
+				Locale:   localeOf(target),
+				Encoding: Encoding,
+				Scale:    Scale,
+				Width:    Width,
+				Columns:  Columns,
+				Muted:    muted(),
+			}
+			segment, err := imageSegment(renderer.RenderImage(snapshot, opts), opts.Encoding)
+			if err != nil {
+				slog.Error(fmt.Sprintf("Status: report %s: %v", report.Name, err))
+				continue
+			}
+			msg.AddImageSegment(segment)
+		}
+		outbox <- msg
+	}
+}
+
+// summaryText names the disks as in snapshot, the redacted snapshot of the card
+func summaryText(s sysinfo.Summary, loc string, snapshot sysinfo.SysInfo, redaction sysinfo.Redaction) string {
+	if s.Samples == 0 {
+		return i18n.T(loc, "report.empty")
+	}
+	lines := []string{
+		i18n.T(loc, "report.summary", format.Duration(loc, s.To.Sub(s.From))),
+		i18n.T(loc, "report.cpu", format.Percent(loc, s.CPU.Min), format.Percent(loc, s.CPU.Avg), format.Percent(loc, s.CPU.Max)),
+		i18n.T(loc, "report.memory", format.Percent(loc, s.Memory.Min), format.Percent(loc, s.Memory.Avg), format.Percent(loc, s.Memory.Max)),
+	}
+	names := map[string]string{}
+	for _, disk := range snapshot.Disks {
+		names[disk.Mount()] = disk.Name
+	}
+	// Disks gone since are redacted as if listed after the snapshot's
+	gone := sysinfo.SysInfo{Disks: make([]sysinfo.DiskInfo, len(snapshot.Disks))}
+	for _, disk := range s.Disks {
+		if _, ok := names[disk.Name]; !ok {
+			gone.Disks = append(gone.Disks, sysinfo.DiskInfo{Name: disk.Name})
+		}
+	}
+	for _, disk := range gone.Redact(redaction).Disks[len(snapshot.Disks):] {
+		names[disk.Mount()] = disk.Name
+	}
+	for _, disk := range s.Disks {
+		growth := "+" + format.Bytes(loc, uint64(disk.Growth()))
+		if disk.Growth() < 0 {
+			growth = "-" + format.Bytes(loc, uint64(-disk.Growth()))
+		}
+		lines = append(lines, i18n.T(loc, "report.disk", names[disk.Name],
+			format.Percent(loc, disk.FromPercent), format.Percent(loc, disk.ToPercent), growth))
+	}
+	lines = append(lines, i18n.T(loc, "report.messages", i18n.Number(loc, float64(s.Received), 0), i18n.Number(loc, float64(s.Sent), 0)))
+	return strings.Join(lines, "\n")
+}
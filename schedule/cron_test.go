@@ -0,0 +1,76 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		ok   bool
+	}{
+		{"* * * * *", true},
+		{"*/15 9-17 * * mon-fri", true},
+		{"5/10 * * * *", true},
+		{"0 0 1 jan,jul *", true},
+		{"0 9 * * 7", true},
+		{"@daily", true},
+		{"@WEEKLY", true},
+		{"* * * *", false},
+		{"60 * * * *", false},
+		{"*/0 * * * *", false},
+		{"0 0 * * fri-mon", false},
+		{"0 0 * foo *", false},
+		{"@fortnightly", false},
+	}
+	for _, test := range tests {
+		if _, err := Parse(test.expr); (err == nil) != test.ok {
+			t.Errorf("Parse(%q) error = %v, want ok %v", test.expr, err, test.ok)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2026-01-01 is a Thursday
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", utc(1, 1, 10, 0).Add(30 * time.Second), utc(1, 1, 10, 1)},
+		{"strictly after", "0 10 * * *", utc(1, 1, 10, 0), utc(1, 2, 10, 0)},
+		{"step", "*/15 * * * *", utc(1, 1, 10, 1), utc(1, 1, 10, 15)},
+		{"step from a value", "5/20 * * * *", utc(1, 1, 10, 30), utc(1, 1, 10, 45)},
+		{"range with step", "0 1-9/4 * * *", utc(1, 1, 5, 30), utc(1, 1, 9, 0)},
+		{"list", "0 8,20 * * *", utc(1, 1, 9, 0), utc(1, 1, 20, 0)},
+		{"day names", "0 9 * * mon-fri", utc(1, 2, 10, 0), utc(1, 5, 9, 0)},
+		{"sunday as 7", "0 9 * * 7", utc(1, 1, 0, 0), utc(1, 4, 9, 0)},
+		{"month names", "0 0 1 mar *", utc(1, 1, 0, 0), utc(3, 1, 0, 0)},
+		{"macro", "@monthly", utc(1, 15, 0, 0), utc(2, 1, 0, 0)},
+		{"day of month or week", "0 0 13 * fri", utc(1, 1, 0, 0), utc(1, 2, 0, 0)},
+		{"never", "0 0 31 2 *", utc(1, 1, 0, 0), time.Time{}},
+		// 02:30 does not exist on 2026-03-08, the clocks go from 02:00 to 03:00
+		{"spring forward", "30 2 * * *", time.Date(2026, 3, 8, 0, 0, 0, 0, newYork), time.Date(2026, 3, 9, 2, 30, 0, 0, newYork)},
+		{"after spring forward", "0 * * * *", time.Date(2026, 3, 8, 1, 30, 0, 0, newYork), time.Date(2026, 3, 8, 3, 0, 0, 0, newYork)},
+		{"fall back", "0 12 * * *", time.Date(2026, 11, 1, 0, 0, 0, 0, newYork), time.Date(2026, 11, 1, 12, 0, 0, 0, newYork)},
+	}
+	for _, test := range tests {
+		c, err := Parse(test.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if got := c.Next(test.from); !got.Equal(test.want) {
+			t.Errorf("%s: Next(%v) = %v, want %v", test.name, test.from, got, test.want)
+		}
+	}
+}
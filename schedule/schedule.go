@@ -0,0 +1,109 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// StateFile keeps the last run of every job across restarts
+var StateFile = filepath.Join("data", "status", "schedule.json")
+
+// Runs missed while the bot was down are made up once on start if younger than CatchUp, 0 skips them
+var CatchUp = 6 * time.Hour
+
+// Job runs at the times of a cron expression
+type Job struct {
+	// Name of the job, unique, it keys the last run
+	Name string
+	Cron Cron
+	// Location the expression is read in, local time when nil
+	Location *time.Location
+	// Run is called with the scheduled time and the previous run, zero if it never ran
+	Run func(at time.Time, previous time.Time)
+}
+
+var lastRuns map[string]time.Time
+var stateLock sync.Mutex
+
+// Start runs a job in the background, first making up the last run missed since the previous start
+func Start(job Job) {
+	loc := job.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	go func() {
+		now := time.Now().In(loc)
+		previous, ok := lastRun(job.Name)
+		if ok {
+			if missed := missedRun(job.Cron, previous.In(loc), now); !missed.IsZero() {
+				if CatchUp > 0 && now.Sub(missed) <= CatchUp {
+					slog.Info(fmt.Sprintf("Status: making up the run of %s missed at %s", job.Name, missed.Format(time.RFC3339)))
+					job.Run(missed, previous)
+					previous = missed
+				} else {
+					slog.Info(fmt.Sprintf("Status: skipping the run of %s missed at %s", job.Name, missed.Format(time.RFC3339)))
+				}
+			}
+		}
+		setLastRun(job.Name, now)
+		for {
+			next := job.Cron.Next(time.Now().In(loc))
+			if next.IsZero() {
+				slog.Warn(fmt.Sprintf("Status: %s will never run again", job.Name))
+				return
+			}
+			time.Sleep(time.Until(next))
+			job.Run(next, previous)
+			setLastRun(job.Name, next)
+			previous = next
+		}
+	}()
+}
+
+// missedRun returns the latest scheduled time after previous and not after now, zero if none was missed
+func missedRun(c Cron, previous time.Time, now time.Time) (missed time.Time) {
+	for next := c.Next(previous); !next.IsZero() && !next.After(now); next = c.Next(next) {
+		missed = next
+	}
+	return
+}
+
+// lastRun returns when a job last ran, reading StateFile the first time
+func lastRun(name string) (time.Time, bool) {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	if lastRuns == nil {
+		lastRuns = map[string]time.Time{}
+		if data, err := os.ReadFile(StateFile); err == nil {
+			if err := json.Unmarshal(data, &lastRuns); err != nil {
+				slog.Error(fmt.Sprintf("Status: failed to read the schedule state: %v", err))
+			}
+		} else if !os.IsNotExist(err) {
+			slog.Error(fmt.Sprintf("Status: failed to read the schedule state: %v", err))
+		}
+	}
+	at, ok := lastRuns[name]
+	return at, ok
+}
+
+func setLastRun(name string, at time.Time) {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	lastRuns[name] = at
+	data, _ := json.MarshalIndent(lastRuns, "", "  ")
+	err := os.MkdirAll(filepath.Dir(StateFile), 0o755)
+	if err == nil {
+		tmp := StateFile + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o644); err == nil {
+			err = os.Rename(tmp, StateFile)
+		}
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("Status: failed to save the schedule state: %v", err))
+	}
+}
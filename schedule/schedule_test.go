@@ -0,0 +1,27 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMissedRun(t *testing.T) {
+	daily, _ := Parse("0 9 * * *")
+	at := func(day, hour int) time.Time { return time.Date(2026, 1, day, hour, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name     string
+		previous time.Time
+		now      time.Time
+		want     time.Time
+	}{
+		{"none missed", at(1, 9), at(1, 12), time.Time{}},
+		{"one missed", at(1, 9), at(2, 12), at(2, 9)},
+		{"latest of several", at(1, 9), at(5, 8), at(4, 9)},
+		{"due now", at(1, 9), at(2, 9), at(2, 9)},
+	}
+	for _, test := range tests {
+		if got := missedRun(daily, test.previous, test.now); !got.Equal(test.want) {
+			t.Errorf("%s: missedRun = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Addr is the address the built-in HTTP server listens on
+var Addr = "127.0.0.1:8780"
+
+// Token required as "Authorization: Bearer <Token>" or "?access_token=<Token>" by protected handlers, none when empty
+var Token = ""
+
+var mux = http.NewServeMux()
+var srv *http.Server
+var cancel context.CancelFunc
+var lock sync.Mutex
+
+// Handle registers a handler, it can be called before or after Start
+func Handle(pattern string, handler http.Handler) {
+	mux.Handle(pattern, handler)
+}
+
+// Protect wraps a handler so it requires the bearer Token, if any
+func Protect(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if Token != "" {
+			// Browsers cannot set headers on EventSource, so the query is accepted too
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				got, ok = r.URL.Query().Get("access_token"), r.URL.Query().Has("access_token")
+			}
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(Token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="status"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Start starts the server in the background, it does nothing if the server is already running
+func Start() error {
+	lock.Lock()
+	defer lock.Unlock()
+	if srv != nil {
+		return nil
+	}
+	// Listen synchronously so the server is reachable once Start returns
+	listener, err := net.Listen("tcp", Addr)
+	if err != nil {
+		return err
+	}
+	// Requests are cancelled on Shutdown, so streams end instead of holding it up
+	ctx, cancelRequests := context.WithCancel(context.Background())
+	srv = &http.Server{Addr: Addr, Handler: mux, BaseContext: func(net.Listener) context.Context { return ctx }}
+	cancel = cancelRequests
+	go func(s *http.Server) {
+		slog.Info(fmt.Sprintf("Status: HTTP server listening on %s", listener.Addr()))
+		if err := s.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error(fmt.Sprintf("Status: HTTP server stopped: %v", err))
+		}
+	}(srv)
+	return nil
+}
+
+// Shutdown gracefully stops the server
+func Shutdown(ctx context.Context) error {
+	lock.Lock()
+	defer lock.Unlock()
+	if srv == nil {
+		return nil
+	}
+	cancel()
+	err := srv.Shutdown(ctx)
+	srv = nil
+	return err
+}
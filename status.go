@@ -1,22 +1,237 @@
 package status
 
 import (
+	"context"
+	"fmt"
+	"image"
+	"log/slog"
+	"strings"
+	"time"
+
 	"github.com/gonebot-dev/gonebot"
+	"github.com/gonebot-dev/gonebot/adapter"
 	"github.com/gonebot-dev/gonebot/message"
 	"github.com/gonebot-dev/gonebot/plugin"
 	"github.com/gonebot-dev/gonebot/plugin/handler"
+	"github.com/gonebot-dev/goneplugin-status/api"
+	"github.com/gonebot-dev/goneplugin-status/dashboard"
+	"github.com/gonebot-dev/goneplugin-status/delivery"
+	"github.com/gonebot-dev/goneplugin-status/history"
+	"github.com/gonebot-dev/goneplugin-status/i18n"
+	"github.com/gonebot-dev/goneplugin-status/metrics"
 	"github.com/gonebot-dev/goneplugin-status/renderer"
+	"github.com/gonebot-dev/goneplugin-status/server"
+	"github.com/gonebot-dev/goneplugin-status/sysinfo"
+	"github.com/gonebot-dev/goneplugin-status/timing"
 )
 
 var TriggerCommand = "status"
 
+// Locale used for the labels, e.g. "en" or "zh-CN".
+// The embedded font has no CJK glyphs, a system one is looked up from renderer.CJKFontPaths, else call
+// renderer.AddFontFile with a CJK font
+var Locale = "en"
+
+// Locales by group ID and by user ID, a user's choice wins over the group's
+var GroupLocales = map[string]string{}
+var UserLocales = map[string]string{}
+
+// Image format and size budget, e.g. {Format: renderer.JPEG, Quality: 85, MaxBytes: 1 << 20}
+var Encoding = renderer.Encoding{Format: renderer.PNG}
+
+// Scale of the image and its width in pixels, e.g. Scale 2 for crisp 2560px images
+var Scale = 1.0
+var Width = 0.0
+
+// Columns of the grid compact panels flow into, 0 picks them from the width and 1 keeps a single column
+var Columns = 0
+
+// View shown by a bare trigger command, "status mini" and "status full" pick one explicitly
+var View = renderer.ViewFull
+
+// Default views by group ID
+var GroupViews = map[string]string{}
+
+// Renders younger than CacheTTL are reused, 0 disables the cache
+var CacheTTL = 5 * time.Second
+
+// How the image is passed to the adapter, see the delivery package
+var Delivery = delivery.Base64
+
+// Delivery modes by adapter name, e.g. {"onebot": delivery.File}
+var AdapterDeliveries = map[string]string{}
+
+// Serve Prometheus metrics at /metrics on the built-in server, see server.Addr
+var EnableMetrics = false
+
+// Serve the JSON API at /api/status, the image at /api/status/image, render timings at /api/status/timings
+// and health checks at /healthz and /readyz.
+// server.Token protects the API and metrics with a bearer token
+var EnableAPI = false
+
+// Serve a live web dashboard at /dashboard/, with "?access_token=" when server.Token is set
+var EnableDashboard = false
+
+// Keep the samples and message counters across restarts for charts, reports and alerts, see the history package
+var EnableHistory = false
+
+// Count messages, uptime and restarts across restarts and show them on the card.
+// Call Stop before the bot exits, otherwise the next start counts as a crash
+var EnableLifetime = false
+
+// Show the messages per minute, today's and this week's counts and the traffic by hour on the card.
+// The counts only reach before the last restart with EnableHistory
+var EnableThroughput = false
+
+// Print how long the card took to collect and draw in its bottom left corner, e.g. "rendered in 412ms"
+var ShowRenderTime = false
+
+// How long Stop waits for running requests
+var ShutdownTimeout = 5 * time.Second
+
+// Redaction policy for the mount paths, hostname, CPU model and activity IDs, applied to the cards, reports,
+// alerts, API, dashboard and metrics. The snapshots hold no addresses or process names to redact
+var Redaction sysinfo.Redaction
+
+// Redaction policies by group ID and by user ID, a user's policy wins over the group's
+var GroupRedactions = map[string]sysinfo.Redaction{}
+var UserRedactions = map[string]sysinfo.Redaction{}
+
 var Status plugin.GonePlugin
 
 func statusHandler(incomingMsg message.Message, resultMsg *message.Message) bool {
-	resultMsg.AddImageSegment(renderer.Render())
+	if a := args(incomingMsg); len(a) > 0 {
+		if command, ok := alertCommands[a[0]]; ok {
+			if !isAdmin(incomingMsg) {
+				resultMsg.AddTextSegment(i18n.T(localeOf(incomingMsg), "alert.denied"))
+				return true
+			}
+			resultMsg.AddTextSegment(command(incomingMsg, a[1:], localeOf(incomingMsg)))
+			return true
+		}
+		if a[0] == renderer.ViewActivity && activityCommand(incomingMsg, a[1:], resultMsg) {
+			return true
+		}
+	}
+	defer timing.Start(timing.Total)()
+	redaction := redactionOf(incomingMsg)
+	opts := renderer.Options{
+		View:     viewOf(incomingMsg),
+		Locale:   localeOf(incomingMsg),
+		Encoding: Encoding,
+		Scale:    Scale,
+		Width:    Width,
+		Columns:  Columns,
+		Muted:    muted(),
+	}
+	render := func() image.Image {
+		start := time.Now()
+		img := renderer.RenderImage(sysinfo.GetSysInfo().Redact(redaction), opts)
+		if ShowRenderTime {
+			img = renderer.DrawRenderTime(img, time.Since(start), opts)
+		}
+		return img
+	}
+	var img image.Image
+	if CacheTTL > 0 {
+		var at time.Time
+		img, at = renderer.Cached(fmt.Sprintf("%s|%+v", opts.CacheKey(), redaction), CacheTTL, render)
+		if age := time.Since(at); age >= time.Second {
+			img = renderer.DrawAge(img, age, opts)
+		}
+	} else {
+		img = render()
+	}
+	segment, err := imageSegment(img, opts.Encoding)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Status: %v", err))
+		return false
+	}
+	resultMsg.AddImageSegment(segment)
 	return true
 }
 
+// imageSegment encodes an image and delivers it to the adapter
+func imageSegment(img image.Image, enc renderer.Encoding) (string, error) {
+	encoded, err := renderer.Encode(img, enc)
+	if err != nil {
+		return "", fmt.Errorf("failed to render: %w", err)
+	}
+	slog.Debug(fmt.Sprintf("Status: rendered %dx%d %s, %d bytes (quality %d, scale %.2f)",
+		encoded.Width, encoded.Height, encoded.Format, encoded.Size(), encoded.Quality, encoded.Scale))
+	if encoded.OverBudget {
+		slog.Warn(fmt.Sprintf("Status: the image is %d bytes at the lowest quality and scale, over the budget of %d", encoded.Size(), enc.MaxBytes))
+	}
+	segment, err := delivery.Deliver(deliveryOf(adapter.GetCurrentAdatper().Name), encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to deliver the image: %w", err)
+	}
+	return segment, nil
+}
+
+// Messages sent on the bot's own initiative, e.g. alerts, waiting for the active handler
+var outbox = make(chan message.Message, 64)
+
+func pushHandler() message.Message {
+	return <-outbox
+}
+
+// targetOf returns a message as if sent by a user or in a group, so the per-user and per-group settings apply
+func targetOf(userID string, groupID string) message.Message {
+	if groupID != "" {
+		return message.Message{IsGroup: true, GroupID: groupID}
+	}
+	return message.Message{SenderID: userID}
+}
+
+// replyTo returns an empty message addressed to a target
+func replyTo(target message.Message) message.Message {
+	return message.Message{ReceiverID: target.SenderID, IsGroup: target.IsGroup, GroupID: target.GroupID}
+}
+
+// args returns the words following the trigger command
+func args(msg message.Message) []string {
+	text := strings.TrimSpace(msg.GetText())
+	return strings.Fields(strings.TrimPrefix(text, TriggerCommand))
+}
+
+func viewOf(msg message.Message) string {
+	if a := args(msg); len(a) > 0 && (a[0] == renderer.ViewFull || a[0] == renderer.ViewMini || a[0] == renderer.ViewActivity) {
+		return a[0]
+	}
+	if view, ok := GroupViews[msg.GroupID]; ok && msg.IsGroup {
+		return view
+	}
+	return View
+}
+
+func deliveryOf(adapterName string) string {
+	if mode, ok := AdapterDeliveries[adapterName]; ok {
+		return mode
+	}
+	return Delivery
+}
+
+func redactionOf(msg message.Message) sysinfo.Redaction {
+	if redaction, ok := UserRedactions[msg.SenderID]; ok {
+		return redaction
+	}
+	if redaction, ok := GroupRedactions[msg.GroupID]; ok && msg.IsGroup {
+		return redaction
+	}
+	return Redaction
+}
+
+func localeOf(msg message.Message) string {
+	if locale, ok := UserLocales[msg.SenderID]; ok {
+		return locale
+	}
+	if locale, ok := GroupLocales[msg.GroupID]; ok && msg.IsGroup {
+		return locale
+	}
+	return Locale
+}
+
 func statusMatcher(incomingMsg message.Message) bool {
 	return incomingMsg.IsToMe && incomingMsg.HasPrefix(TriggerCommand)
 }
@@ -25,13 +240,72 @@ func init() {
 	Status.Name = "Status"
 	Status.Version = "v0.2.0"
 	Status.Description = "Show bot status"
+	sysinfo.RegisterPlugin(Status.Name, Status.Version, Status.Description)
 
+	// The activity handler goes first, as it never stops the others
 	Status.Handlers = append(Status.Handlers, handler.GoneHandler{
-		Matcher: statusMatcher,
-		Handler: statusHandler,
+		Matcher: handler.MatchAll,
+		Handler: activityHandler,
+	}, handler.GoneHandler{
+		Matcher: trackMatcher(Status.Name, statusMatcher),
+		Handler: trackHandler(Status.Name, statusHandler),
+	})
+	Status.ActiveHandlers = append(Status.ActiveHandlers, handler.GoneActiveHandler{
+		Handler: pushHandler,
 	})
 }
 
 func Load() {
 	gonebot.LoadPlugin(Status)
+	if EnableLifetime {
+		sysinfo.StartLifetime()
+	}
+	if EnableHistory {
+		history.Start()
+	}
+	if EnableThroughput {
+		sysinfo.StartThroughput()
+	}
+	if EnableActivity {
+		sysinfo.StartActivity()
+	}
+	if EnablePlugins {
+		sysinfo.StartPlugins()
+	}
+	if EnableAlerts {
+		startAlerts()
+	}
+	startReports()
+	if EnableMetrics {
+		metrics.Register(Redaction)
+	}
+	if EnableAPI {
+		api.CacheTTL = CacheTTL
+		api.Register(renderer.Options{
+			View:    View,
+			Locale:  Locale,
+			Scale:   Scale,
+			Width:   Width,
+			Columns: Columns,
+		}, Redaction)
+	}
+	if EnableDashboard {
+		dashboard.Register(Locale, Redaction)
+	}
+	if EnableMetrics || EnableAPI || EnableDashboard {
+		if err := server.Start(); err != nil {
+			slog.Error(fmt.Sprintf("Status: failed to start the HTTP server: %v", err))
+		}
+	}
+}
+
+// Stop gracefully shuts the HTTP server down and saves the lifetime and activity counters, call it before the bot exits
+func Stop() {
+	sysinfo.StopLifetime()
+	sysinfo.StopActivity()
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error(fmt.Sprintf("Status: failed to stop the HTTP server: %v", err))
+	}
 }
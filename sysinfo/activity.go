@@ -0,0 +1,193 @@
+package sysinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gonebot-dev/gonebot/message"
+)
+
+// ActivityFile keeps the activity counts and opt-outs across restarts
+var ActivityFile = filepath.Join("data", "status", "activity.json")
+
+// How often the activity counts are saved when they changed
+var ActivitySaveInterval = time.Minute
+
+// ActivityTop is how many groups, users and plugins are ranked
+var ActivityTop = 5
+
+// Ranking is a group, user or plugin and its count
+type Ranking struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// Activity ranks who and what drives the bot's traffic
+type Activity struct {
+	// Top groups and users by messages received, and plugins by messages matched
+	Groups  []Ranking `json:"groups"`
+	Users   []Ranking `json:"users"`
+	Plugins []Ranking `json:"plugins"`
+	// Counts of every group, user and plugin, for their shares
+	GroupTotal  int64 `json:"groupTotal"`
+	UserTotal   int64 `json:"userTotal"`
+	PluginTotal int64 `json:"pluginTotal"`
+}
+
+// activityCounts is the content of ActivityFile
+type activityCounts struct {
+	Groups  map[string]int64 `json:"groups"`
+	Users   map[string]int64 `json:"users"`
+	Plugins map[string]int64 `json:"plugins"`
+	// Users who opted out themselves
+	OptedOut map[string]bool `json:"optedOut"`
+}
+
+var activity = activityCounts{
+	Groups:   map[string]int64{},
+	Users:    map[string]int64{},
+	Plugins:  map[string]int64{},
+	OptedOut: map[string]bool{},
+}
+var activityStarted bool
+var activityDirty bool
+var activityLock sync.Mutex
+var activityOnce sync.Once
+
+// StartActivity restores the activity counts and saves them every ActivitySaveInterval, it can be called many times
+func StartActivity() {
+	activityOnce.Do(func() {
+		activityLock.Lock()
+		defer activityLock.Unlock()
+		if data, err := os.ReadFile(ActivityFile); err == nil {
+			if err := json.Unmarshal(data, &activity); err != nil {
+				slog.Error(fmt.Sprintf("Status: failed to read the activity counts: %v", err))
+			}
+		} else if !os.IsNotExist(err) {
+			slog.Error(fmt.Sprintf("Status: failed to read the activity counts: %v", err))
+		}
+		// Files of older versions miss some maps
+		for _, m := range []*map[string]int64{&activity.Groups, &activity.Users, &activity.Plugins} {
+			if *m == nil {
+				*m = map[string]int64{}
+			}
+		}
+		if activity.OptedOut == nil {
+			activity.OptedOut = map[string]bool{}
+		}
+		activityStarted = true
+		go func() {
+			for range time.Tick(ActivitySaveInterval) {
+				activityLock.Lock()
+				if activityDirty {
+					saveActivity()
+				}
+				activityLock.Unlock()
+			}
+		}()
+	})
+}
+
+// StopActivity saves the activity counts
+func StopActivity() {
+	activityLock.Lock()
+	defer activityLock.Unlock()
+	if activityStarted && activityDirty {
+		saveActivity()
+	}
+}
+
+// saveActivity writes the counts, the caller holds activityLock
+func saveActivity() {
+	data, _ := json.Marshal(activity)
+	err := os.MkdirAll(filepath.Dir(ActivityFile), 0o755)
+	if err == nil {
+		tmp := ActivityFile + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o644); err == nil {
+			err = os.Rename(tmp, ActivityFile)
+		}
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("Status: failed to save the activity counts: %v", err))
+		return
+	}
+	activityDirty = false
+}
+
+// RecordActivity counts an incoming message for its group and sender, unless the sender opted out
+func RecordActivity(msg message.Message) {
+	activityLock.Lock()
+	defer activityLock.Unlock()
+	if !activityStarted {
+		return
+	}
+	if msg.IsGroup && msg.GroupID != "" {
+		activity.Groups[msg.GroupID]++
+	}
+	if msg.SenderID != "" && !activity.OptedOut[msg.SenderID] {
+		activity.Users[msg.SenderID]++
+	}
+	activityDirty = true
+}
+
+// RecordMatch counts a message matched by a plugin
+func RecordMatch(plugin string) {
+	activityLock.Lock()
+	defer activityLock.Unlock()
+	if !activityStarted {
+		return
+	}
+	activity.Plugins[plugin]++
+	activityDirty = true
+}
+
+// OptOut stops or resumes counting a user, opting out also forgets the user's count
+func OptOut(user string, out bool) {
+	activityLock.Lock()
+	defer activityLock.Unlock()
+	if out {
+		activity.OptedOut[user] = true
+		delete(activity.Users, user)
+	} else {
+		delete(activity.OptedOut, user)
+	}
+	activityDirty = true
+}
+
+// ActivityStats returns the activity ranking, false if StartActivity was not called
+func ActivityStats() (Activity, bool) {
+	activityLock.Lock()
+	defer activityLock.Unlock()
+	if !activityStarted {
+		return Activity{}, false
+	}
+	a := Activity{}
+	a.Groups, a.GroupTotal = rank(activity.Groups)
+	a.Users, a.UserTotal = rank(activity.Users)
+	a.Plugins, a.PluginTotal = rank(activity.Plugins)
+	return a, true
+}
+
+// rank returns the ActivityTop highest counts, ties by name, and the sum of every count
+func rank(counts map[string]int64) (top []Ranking, total int64) {
+	for name, count := range counts {
+		top = append(top, Ranking{name, count})
+		total += count
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Name < top[j].Name
+	})
+	if len(top) > ActivityTop {
+		top = top[:ActivityTop]
+	}
+	return
+}
@@ -0,0 +1,128 @@
+package sysinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gonebot-dev/gonebot/utils"
+)
+
+// LifetimeFile keeps the counters across restarts
+var LifetimeFile = filepath.Join("data", "status", "lifetime.json")
+
+// How often the lifetime counters are saved, a crash loses at most this much
+var LifetimeSaveInterval = time.Minute
+
+// Reasons of the last restart
+const (
+	RestartFirst = "first"
+	// The bot was stopped with StopLifetime
+	RestartClean = "clean"
+	// The bot stopped without StopLifetime, its dirty marker was left behind
+	RestartCrash = "crash"
+)
+
+// Lifetime counts across every run of the bot
+type Lifetime struct {
+	Received int64 `json:"received"`
+	Sent     int64 `json:"sent"`
+	// Bot uptime of every run, in seconds
+	Uptime            int64     `json:"uptime"`
+	Restarts          int       `json:"restarts"`
+	LastRestart       time.Time `json:"lastRestart"`
+	LastRestartReason string    `json:"lastRestartReason"`
+}
+
+// Counters of the previous runs
+var lifetime Lifetime
+var lifetimeStarted bool
+var lifetimeLock sync.Mutex
+var lifetimeOnce sync.Once
+
+// dirtyMarker exists while the bot runs, so a crash can be told from a clean stop
+func dirtyMarker() string {
+	return LifetimeFile + ".running"
+}
+
+// StartLifetime restores the lifetime counters and saves them every LifetimeSaveInterval, it can be called many times
+func StartLifetime() {
+	lifetimeOnce.Do(func() {
+		lifetimeLock.Lock()
+		defer lifetimeLock.Unlock()
+		lifetime.LastRestartReason = RestartFirst
+		if data, err := os.ReadFile(LifetimeFile); err == nil {
+			if err := json.Unmarshal(data, &lifetime); err != nil {
+				slog.Error(fmt.Sprintf("Status: failed to read the lifetime counters: %v", err))
+			}
+			lifetime.Restarts++
+			lifetime.LastRestartReason = RestartClean
+			if _, err := os.Stat(dirtyMarker()); err == nil {
+				lifetime.LastRestartReason = RestartCrash
+			}
+		}
+		lifetime.LastRestart = time.Unix(start, 0)
+		lifetimeStarted = true
+		if err := saveLifetime(); err != nil {
+			slog.Error(fmt.Sprintf("Status: failed to save the lifetime counters: %v", err))
+		}
+		if err := os.WriteFile(dirtyMarker(), nil, 0o644); err != nil {
+			slog.Error(fmt.Sprintf("Status: failed to write the dirty marker: %v", err))
+		}
+		go func() {
+			for range time.Tick(LifetimeSaveInterval) {
+				lifetimeLock.Lock()
+				if err := saveLifetime(); err != nil {
+					slog.Error(fmt.Sprintf("Status: failed to save the lifetime counters: %v", err))
+				}
+				lifetimeLock.Unlock()
+			}
+		}()
+	})
+}
+
+// StopLifetime saves the lifetime counters and removes the dirty marker, so the next start counts as clean
+func StopLifetime() {
+	lifetimeLock.Lock()
+	defer lifetimeLock.Unlock()
+	if !lifetimeStarted {
+		return
+	}
+	if err := saveLifetime(); err != nil {
+		slog.Error(fmt.Sprintf("Status: failed to save the lifetime counters: %v", err))
+	}
+	os.Remove(dirtyMarker())
+}
+
+// current returns the counters of the previous runs plus this one, the caller holds lifetimeLock
+func current() Lifetime {
+	l := lifetime
+	l.Received += int64(utils.GetIncomingCount())
+	l.Sent += int64(utils.GetResultCount())
+	l.Uptime += time.Now().Unix() - start
+	return l
+}
+
+// saveLifetime writes the current counters, the caller holds lifetimeLock
+func saveLifetime() error {
+	data, _ := json.MarshalIndent(current(), "", "  ")
+	if err := os.MkdirAll(filepath.Dir(LifetimeFile), 0o755); err != nil {
+		return err
+	}
+	tmp := LifetimeFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, LifetimeFile)
+}
+
+// LifetimeStats returns the lifetime counters, false if StartLifetime was not called
+func LifetimeStats() (Lifetime, bool) {
+	lifetimeLock.Lock()
+	defer lifetimeLock.Unlock()
+	return current(), lifetimeStarted
+}
@@ -0,0 +1,73 @@
+package sysinfo
+
+import (
+	"sync"
+	"time"
+)
+
+// PluginStats is a loaded plugin and how its handlers did
+type PluginStats struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	// Handler calls, the ones that panicked, and their total run time
+	Invocations int64         `json:"invocations"`
+	Errors      int64         `json:"errors"`
+	Latency     time.Duration `json:"latency"`
+}
+
+// AvgLatency is the average run time of a handler call
+func (p PluginStats) AvgLatency() time.Duration {
+	if p.Invocations == 0 {
+		return 0
+	}
+	return p.Latency / time.Duration(p.Invocations)
+}
+
+// Plugins in the order they were registered
+var plugins []PluginStats
+var pluginsStarted bool
+var pluginsLock sync.Mutex
+
+// StartPlugins adds the registered plugins to the snapshots
+func StartPlugins() {
+	pluginsLock.Lock()
+	defer pluginsLock.Unlock()
+	pluginsStarted = true
+}
+
+// RegisterPlugin adds a plugin to the list, registering a name again updates it
+func RegisterPlugin(name string, version string, description string) {
+	pluginsLock.Lock()
+	defer pluginsLock.Unlock()
+	for i := range plugins {
+		if plugins[i].Name == name {
+			plugins[i].Version, plugins[i].Description = version, description
+			return
+		}
+	}
+	plugins = append(plugins, PluginStats{Name: name, Version: version, Description: description})
+}
+
+// RecordInvocation counts a handler call of a registered plugin
+func RecordInvocation(name string, latency time.Duration, failed bool) {
+	pluginsLock.Lock()
+	defer pluginsLock.Unlock()
+	for i := range plugins {
+		if plugins[i].Name == name {
+			plugins[i].Invocations++
+			plugins[i].Latency += latency
+			if failed {
+				plugins[i].Errors++
+			}
+			return
+		}
+	}
+}
+
+// Plugins returns the registered plugins, false if StartPlugins was not called
+func Plugins() ([]PluginStats, bool) {
+	pluginsLock.Lock()
+	defer pluginsLock.Unlock()
+	return append([]PluginStats(nil), plugins...), pluginsStarted
+}
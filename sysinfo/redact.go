@@ -0,0 +1,91 @@
+package sysinfo
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Action tells what to do with a sensitive field
+type Action int
+
+const (
+	// Keep the field as is
+	Keep Action = iota
+	// Mask replaces the field with a placeholder
+	Mask
+	// Omit clears the field
+	Omit
+)
+
+// Redaction is a policy for the sensitive fields of a snapshot
+type Redaction struct {
+	MountPaths Action `json:"mountPaths"`
+	Hostname   Action `json:"hostname"`
+	CpuModel   Action `json:"cpuModel"`
+	// User and group IDs of the activity board, masked ones get a pseudonym.
+	// They are masked when kept while any other field is redacted
+	IDs Action `json:"ids"`
+}
+
+// Redact returns a copy of info with the policy applied
+func (info SysInfo) Redact(r Redaction) SysInfo {
+	info.Disks = append([]DiskInfo(nil), info.Disks...)
+	for i := range info.Disks {
+		info.Disks[i].mount = info.Disks[i].Mount()
+		info.Disks[i].Name = redact(r.MountPaths, info.Disks[i].Name, fmt.Sprintf("disk%d", i+1))
+	}
+	info.Hostname = redact(r.Hostname, info.Hostname, "localhost")
+	info.CpuInfo = redact(r.CpuModel, info.CpuInfo, "CPU")
+	// Disk errors name the mount path
+	if _, ok := info.Errors["disk"]; ok && r.MountPaths != Keep {
+		errors := map[string]string{}
+		for collector, err := range info.Errors {
+			errors[collector] = err
+		}
+		errors["disk"] = "unavailable"
+		info.Errors = errors
+	}
+	ids := r.IDs
+	if ids == Keep && (r.MountPaths != Keep || r.Hostname != Keep || r.CpuModel != Keep) {
+		ids = Mask
+	}
+	if info.Activity != nil && ids != Keep {
+		a := *info.Activity
+		a.Groups, a.Users = nil, nil
+		if ids == Mask {
+			for _, g := range info.Activity.Groups {
+				a.Groups = append(a.Groups, Ranking{pseudonym("group", g.Name), g.Count})
+			}
+			for _, u := range info.Activity.Users {
+				a.Users = append(a.Users, Ranking{pseudonym("user", u.Name), u.Count})
+			}
+		}
+		info.Activity = &a
+	}
+	return info
+}
+
+// Numeric IDs are easy to guess from a bare hash, so pseudonyms are salted
+var pseudonymSalt = func() []byte {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	return salt
+}()
+
+// pseudonym names an ID by its salted hash, the same ID gets the same name until a restart
+func pseudonym(kind string, id string) string {
+	sum := sha256.Sum256(append(append([]byte(nil), pseudonymSalt...), id...))
+	return kind + "-" + hex.EncodeToString(sum[:3])
+}
+
+func redact(action Action, value string, placeholder string) string {
+	switch action {
+	case Mask:
+		return placeholder
+	case Omit:
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,45 @@
+package sysinfo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactIDs(t *testing.T) {
+	info := SysInfo{Activity: &Activity{
+		Groups: []Ranking{{"123456", 3}},
+		Users:  []Ranking{{"654321", 2}},
+	}}
+	tests := []struct {
+		name      string
+		redaction Redaction
+		// Prefix of the names, empty when they are omitted
+		group, user string
+	}{
+		{"kept", Redaction{}, "123456", "654321"},
+		{"masked", Redaction{IDs: Mask}, "group-", "user-"},
+		{"omitted", Redaction{IDs: Omit}, "", ""},
+		{"masked along with other fields", Redaction{Hostname: Mask}, "group-", "user-"},
+		{"omitted along with other fields", Redaction{MountPaths: Omit, IDs: Omit}, "", ""},
+	}
+	for _, test := range tests {
+		a := info.Redact(test.redaction).Activity
+		for _, board := range []struct {
+			rankings []Ranking
+			want     string
+		}{{a.Groups, test.group}, {a.Users, test.user}} {
+			if board.want == "" {
+				if len(board.rankings) != 0 {
+					t.Errorf("%s: got %v, want none", test.name, board.rankings)
+				}
+				continue
+			}
+			if len(board.rankings) != 1 || !strings.HasPrefix(board.rankings[0].Name, board.want) {
+				t.Errorf("%s: got %v, want a name starting with %q", test.name, board.rankings, board.want)
+			}
+		}
+	}
+	if info.Activity.Groups[0].Name != "123456" {
+		t.Error("Redact changed the original snapshot")
+	}
+}
@@ -0,0 +1,90 @@
+package sysinfo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gonebot-dev/goneplugin-status/timing"
+)
+
+// SampleInterval is how often the background sampler collects a snapshot
+var SampleInterval = 15 * time.Second
+
+// HistorySize is how many samples are kept in memory, an hour at the default interval
+var HistorySize = 240
+
+// Sample is a snapshot and when it was taken
+type Sample struct {
+	SysInfo
+	At time.Time `json:"sampledAt"`
+}
+
+var latest Sample
+var history []Sample
+var subscribers = map[chan Sample]struct{}{}
+var latestLock sync.RWMutex
+var samplerOnce sync.Once
+
+// StartSampler collects a snapshot every SampleInterval in the background, it can be called many times
+func StartSampler() {
+	samplerOnce.Do(func() {
+		sample()
+		go func() {
+			for range time.Tick(SampleInterval) {
+				sample()
+			}
+		}()
+	})
+}
+
+func sample() {
+	// Timed apart from the renders, so their quantiles only count the snapshots users wait for
+	s := Sample{collect(timing.Sample), time.Now()}
+	recordCounter(s)
+	latestLock.Lock()
+	defer latestLock.Unlock()
+	latest = s
+	history = append(history, s)
+	if len(history) > HistorySize {
+		history = append([]Sample(nil), history[len(history)-HistorySize:]...)
+	}
+	for ch := range subscribers {
+		// Slow subscribers miss samples rather than block the sampler
+		select {
+		case ch <- s:
+		default:
+		}
+	}
+}
+
+// Latest returns the last sampled snapshot and when it was taken, sampling now if there is none yet
+func Latest() (SysInfo, time.Time) {
+	latestLock.RLock()
+	s := latest
+	latestLock.RUnlock()
+	if s.At.IsZero() {
+		sample()
+		return Latest()
+	}
+	return s.SysInfo, s.At
+}
+
+// History returns the samples kept in memory, oldest first
+func History() []Sample {
+	latestLock.RLock()
+	defer latestLock.RUnlock()
+	return append([]Sample(nil), history...)
+}
+
+// Subscribe returns a channel receiving every new sample, and a function to stop receiving them
+func Subscribe() (<-chan Sample, func()) {
+	ch := make(chan Sample, 1)
+	latestLock.Lock()
+	subscribers[ch] = struct{}{}
+	latestLock.Unlock()
+	return ch, func() {
+		latestLock.Lock()
+		delete(subscribers, ch)
+		latestLock.Unlock()
+	}
+}
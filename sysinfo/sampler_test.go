@@ -0,0 +1,29 @@
+package sysinfo
+
+import (
+	"testing"
+
+	"github.com/gonebot-dev/goneplugin-status/timing"
+)
+
+func TestSampleTiming(t *testing.T) {
+	count := func(name string) int64 { return timing.Snapshot()[name].Count }
+	collects, samples, cpus := count(timing.Collect), count(timing.Sample), count(timing.Collect+".cpu")
+	sample()
+	if got := count(timing.Collect); got != collects {
+		t.Errorf("sampling recorded %d collect timings, want none", got-collects)
+	}
+	if got := count(timing.Collect + ".cpu"); got != cpus {
+		t.Errorf("sampling recorded %d collect.cpu timings, want none", got-cpus)
+	}
+	if got := count(timing.Sample); got != samples+1 {
+		t.Errorf("sampling recorded %d sample timings, want 1", got-samples)
+	}
+	if got := count(timing.Sample + ".cpu"); got == 0 {
+		t.Error("sampling recorded no sample.cpu timings")
+	}
+	GetSysInfo()
+	if got := count(timing.Collect); got != collects+1 {
+		t.Errorf("GetSysInfo recorded %d collect timings, want 1", got-collects)
+	}
+}
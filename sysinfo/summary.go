@@ -0,0 +1,106 @@
+package sysinfo
+
+import "time"
+
+// Stat is the minimum, average and maximum of a metric
+type Stat struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+
+	sum float64
+	n   int
+}
+
+func (s *Stat) add(v float64) {
+	if s.n == 0 || v < s.Min {
+		s.Min = v
+	}
+	if s.n == 0 || v > s.Max {
+		s.Max = v
+	}
+	s.sum += v
+	s.n++
+	s.Avg = s.sum / float64(s.n)
+}
+
+// Merge adds n values whose minimum, average and maximum are known
+func (s *Stat) Merge(min float64, avg float64, max float64, n int) {
+	if n <= 0 {
+		return
+	}
+	if s.n == 0 || min < s.Min {
+		s.Min = min
+	}
+	if s.n == 0 || max > s.Max {
+		s.Max = max
+	}
+	s.sum += avg * float64(n)
+	s.n += n
+	s.Avg = s.sum / float64(s.n)
+}
+
+// DiskGrowth is how much a partition grew over a summary
+type DiskGrowth struct {
+	Name string `json:"name"`
+	// Used bytes and percent at the first and last samples
+	FromUsed    uint64  `json:"fromUsed"`
+	ToUsed      uint64  `json:"toUsed"`
+	FromPercent float64 `json:"fromPercent"`
+	ToPercent   float64 `json:"toPercent"`
+}
+
+// Growth in bytes, negative when the partition shrank
+func (d DiskGrowth) Growth() int64 {
+	return int64(d.ToUsed) - int64(d.FromUsed)
+}
+
+// Summary aggregates samples over a period
+type Summary struct {
+	From    time.Time    `json:"from"`
+	To      time.Time    `json:"to"`
+	Samples int          `json:"samples"`
+	CPU     Stat         `json:"cpu"`
+	Memory  Stat         `json:"memory"`
+	Disks   []DiskGrowth `json:"disks"`
+	// Messages received and sent over the period
+	Received int `json:"received"`
+	Sent     int `json:"sent"`
+
+	last Sample
+}
+
+// Add adds a sample to the summary
+func (s *Summary) Add(sample Sample) {
+	if s.Samples == 0 {
+		s.From = sample.At
+	}
+	s.To = sample.At
+	s.CPU.add(sample.CpuUsedPercent)
+	s.Memory.add(sample.MemUsedPercent)
+	for _, disk := range sample.Disks {
+		found := false
+		for i := range s.Disks {
+			if s.Disks[i].Name == disk.Name {
+				s.Disks[i].ToUsed, s.Disks[i].ToPercent = disk.Used, disk.UsedPercent
+				found = true
+				break
+			}
+		}
+		if !found {
+			s.Disks = append(s.Disks, DiskGrowth{
+				Name:        disk.Name,
+				FromUsed:    disk.Used,
+				ToUsed:      disk.Used,
+				FromPercent: disk.UsedPercent,
+				ToPercent:   disk.UsedPercent,
+			})
+		}
+	}
+	if s.Samples > 0 {
+		s.Received += sample.ReceivedTotal - s.last.ReceivedTotal
+		s.Sent += sample.SentTotal - s.last.SentTotal
+	}
+	s.last = sample
+	s.Samples++
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gonebot-dev/gonebot/adapter"
 	"github.com/gonebot-dev/gonebot/utils"
+	"github.com/gonebot-dev/goneplugin-status/timing"
 	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/disk"
 	"github.com/shirou/gopsutil/host"
@@ -17,25 +18,35 @@ import (
 
 var start = time.Now().Unix()
 
-type diskInfo struct {
+// DiskInfo is the usage of a mounted partition
+type DiskInfo struct {
 	Name        string  `json:"name"`
 	Total       uint64  `json:"total"`
 	Used        uint64  `json:"used"`
 	UsedPercent float64 `json:"usedPercent"`
+
+	// Mount path before redaction, empty when Name was not redacted
+	mount string
+}
+
+// Mount returns the real mount path, even after Name was redacted, for looking up per-mount settings
+func (d DiskInfo) Mount() string {
+	if d.mount != "" {
+		return d.mount
+	}
+	return d.Name
 }
 
-type sysInfo struct {
+// SysInfo is a snapshot of the system and bot status
+type SysInfo struct {
 	// Disk
-	Disks []diskInfo `json:"disks"`
+	Disks []DiskInfo `json:"disks"`
 	// Mem
 	MemAll         uint64  `json:"memAll"`
 	MemUsed        uint64  `json:"memUsed"`
 	MemUsedPercent float64 `json:"memUsedPercent"`
-	// Boot time
-	Days    int64 `json:"days"`
-	Hours   int64 `json:"hours"`
-	Minutes int64 `json:"minutes"`
-	Seconds int64 `json:"seconds"`
+	// Boot time, in seconds
+	Uptime int64 `json:"uptime"`
 	// CPU
 	CpuUsedPercent float64 `json:"cpuUsedPercent"`
 	CpuCores       int     `json:"cpuCores"`
@@ -46,88 +57,152 @@ type sysInfo struct {
 	// OS
 	OS   string `json:"os"`
 	Arch string `json:"arch"`
+	// Host
+	Hostname        string `json:"hostname"`
+	Platform        string `json:"platform"`
+	PlatformFamily  string `json:"platformFamily"`
+	PlatformVersion string `json:"platformVersion"`
+	KernelVersion   string `json:"kernelVersion"`
+	// Virtualization or container type, e.g. "kvm" or "docker", and "host" or "guest"
+	VirtualizationSystem string `json:"virtualizationSystem"`
+	VirtualizationRole   string `json:"virtualizationRole"`
 	// Gonebot
 	SentTotal     int    `json:"sentTotal"`
 	ReceivedTotal int    `json:"receivedTotal"`
 	Backend       string `json:"backend"`
-	BotDays       int64  `json:"botDays"`
-	BotHours      int64  `json:"botHours"`
-	BotMinutes    int64  `json:"botMinutes"`
-	BotSeconds    int64  `json:"botSeconds"`
+	BotUptime     int64  `json:"botUptime"`
+	// Counters across restarts, nil unless StartLifetime was called
+	Lifetime *Lifetime `json:"lifetime,omitempty"`
+	// Message traffic, nil unless StartThroughput was called
+	Throughput *Throughput `json:"throughput,omitempty"`
+	// Top groups, users and plugins, nil unless StartActivity was called
+	Activity *Activity `json:"activity,omitempty"`
+	// Plugins wrapped by the status plugin, nil unless StartPlugins was called
+	Plugins []PluginStats `json:"plugins,omitempty"`
+	// Errors of the collectors by name, e.g. "disk" or "cpu", the matching fields are left empty
+	Errors map[string]string `json:"errors,omitempty"`
 }
 
-func GetSysInfo() (info sysInfo) {
-	unit := uint64(1024 * 1024) // MB
+// fail records the error of a collector, keeping the first one
+func (info *SysInfo) fail(collector string, err error) {
+	if info.Errors == nil {
+		info.Errors = map[string]string{}
+	}
+	if _, ok := info.Errors[collector]; !ok {
+		info.Errors[collector] = err.Error()
+	}
+}
+
+// GetSysInfo collects a snapshot, timed as timing.Collect
+func GetSysInfo() SysInfo {
+	return collect(timing.Collect)
+}
+
+// collect collects a snapshot, timed as phase and its collectors as phase + "." + their name
+func collect(phase string) (info SysInfo) {
+	defer timing.Start(phase)()
 
 	// Disks
-	infos, _ := disk.Partitions(false)
+	done := timing.Start(phase + ".disk")
+	infos, err := disk.Partitions(false)
+	if err != nil {
+		info.fail("disk", err)
+	}
 	for _, inf := range infos {
 		diskStat, err := disk.Usage(inf.Mountpoint)
 		if err != nil {
-			fmt.Println(err)
-			panic(err)
+			info.fail("disk", fmt.Errorf("%s: %w", inf.Mountpoint, err))
+			continue
 		}
-		info.Disks = append(info.Disks, diskInfo{
+		info.Disks = append(info.Disks, DiskInfo{
 			Name:        inf.Mountpoint,
-			Total:       diskStat.Total / unit,
-			Used:        diskStat.Used / unit,
+			Total:       diskStat.Total,
+			Used:        diskStat.Used,
 			UsedPercent: diskStat.UsedPercent,
 		})
 	}
+	done()
 
 	// Mem
-	v, _ := mem.VirtualMemory()
-	info.MemAll = v.Total
-	info.MemUsed = info.MemAll - v.Free
-	info.MemUsedPercent = float64(info.MemUsed) / float64(info.MemAll) * 100.0
-	info.MemAll /= unit
-	info.MemUsed /= unit
+	done = timing.Start(phase + ".memory")
+	if v, err := mem.VirtualMemory(); err == nil && v.Total > 0 {
+		info.MemAll = v.Total
+		info.MemUsed = info.MemAll - v.Free
+		info.MemUsedPercent = float64(info.MemUsed) / float64(info.MemAll) * 100.0
+	} else if err != nil {
+		info.fail("memory", err)
+	}
+	done()
 
 	// CPU
+	done = timing.Start(phase + ".cpu")
 	info.CpuCores, _ = cpu.Counts(true)
-	cc, _ := cpu.Percent(time.Millisecond*200, false) //CPU usage in 200ms
-	info.CpuUsedPercent = cc[0]
-	dat, _ := cpu.Info()
-	reg := regexp.MustCompile(`( @ ).*Hz`)
-	info.CpuInfo = reg.ReplaceAllString(dat[0].ModelName, "")
-	stat, _ := load.Avg()
-	info.CpuLoad1 = stat.Load1
-	info.CpuLoad5 = stat.Load5
-	info.CpuLoad15 = stat.Load15
+	cc, err := cpu.Percent(time.Millisecond*200, false) //CPU usage in 200ms
+	if err == nil && len(cc) > 0 {
+		info.CpuUsedPercent = cc[0]
+	} else if err != nil {
+		info.fail("cpu", err)
+	}
+	if dat, err := cpu.Info(); err == nil && len(dat) > 0 {
+		reg := regexp.MustCompile(`( @ ).*Hz`)
+		info.CpuInfo = reg.ReplaceAllString(dat[0].ModelName, "")
+	} else if err != nil {
+		info.fail("cpu", err)
+	}
+	done()
+	done = timing.Start(phase + ".load")
+	if stat, err := load.Avg(); err == nil {
+		info.CpuLoad1 = stat.Load1
+		info.CpuLoad5 = stat.Load5
+		info.CpuLoad15 = stat.Load15
+	} else {
+		info.fail("load", err)
+	}
+	done()
 
 	// OS
 	info.OS = runtime.GOOS
 	info.Arch = runtime.GOARCH
 
+	// Host
+	done = timing.Start(phase + ".host")
+	if hostInfo, err := host.Info(); err != nil {
+		info.fail("host", err)
+	} else {
+		info.Hostname = hostInfo.Hostname
+		info.Platform = hostInfo.Platform
+		info.PlatformFamily = hostInfo.PlatformFamily
+		info.PlatformVersion = hostInfo.PlatformVersion
+		info.KernelVersion = hostInfo.KernelVersion
+		info.VirtualizationSystem = hostInfo.VirtualizationSystem
+		info.VirtualizationRole = hostInfo.VirtualizationRole
+	}
+
 	// 获取开机时间
-	boottime, _ := host.BootTime()
 	ntime := time.Now().Unix()
-	btime := time.Unix(int64(boottime), 0).Unix()
-	deltatime := ntime - btime
-
-	info.Seconds = int64(deltatime)
-	info.Minutes = info.Seconds / 60
-	info.Seconds -= info.Minutes * 60
-	info.Hours = info.Minutes / 60
-	info.Minutes -= info.Hours * 60
-	info.Days = info.Hours / 24
-	info.Hours -= info.Days * 24
-
-	ntime = time.Now().Unix()
-	btime = start
-	deltatime = ntime - btime
-
-	info.BotSeconds = int64(deltatime)
-	info.BotMinutes = info.BotSeconds / 60
-	info.BotSeconds -= info.BotMinutes * 60
-	info.BotHours = info.BotMinutes / 60
-	info.BotMinutes -= info.BotHours * 60
-	info.BotDays = info.BotHours / 24
-	info.BotHours -= info.BotDays * 24
+	if boottime, err := host.BootTime(); err == nil {
+		info.Uptime = ntime - int64(boottime)
+	} else {
+		info.fail("host", err)
+	}
+	done()
+	info.BotUptime = ntime - start
 
 	info.SentTotal = utils.GetResultCount()
 	info.ReceivedTotal = utils.GetIncomingCount()
 	info.Backend = adapter.GetCurrentAdatper().Name
+	if l, ok := LifetimeStats(); ok {
+		info.Lifetime = &l
+	}
+	if t, ok := ThroughputStats(time.Now()); ok {
+		info.Throughput = &t
+	}
+	if a, ok := ActivityStats(); ok {
+		info.Activity = &a
+	}
+	if p, ok := Plugins(); ok {
+		info.Plugins = p
+	}
 
 	return
 }
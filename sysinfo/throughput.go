@@ -0,0 +1,121 @@
+package sysinfo
+
+import (
+	"sync"
+	"time"
+)
+
+// Counter is the messages received and sent together at a time
+type Counter struct {
+	At    time.Time
+	Total int64
+}
+
+// Throughput is the message traffic, received and sent messages counted together
+type Throughput struct {
+	// Messages per minute over the last minute, and over the busiest minute of the last hour
+	PerMinute     float64 `json:"perMinute"`
+	PeakPerMinute float64 `json:"peakPerMinute"`
+	// Messages since midnight and since Monday
+	Today int64 `json:"today"`
+	Week  int64 `json:"week"`
+	// Messages in each of the last 24 hours, oldest first, the last one is the current hour
+	Hourly []int64 `json:"hourly"`
+	// Start of the first hour
+	HourlyFrom time.Time `json:"hourlyFrom"`
+}
+
+// Counters of the last 8 days, the last one of every minute
+var counters []Counter
+
+// Total of a sample's counter, the messages since the start unless SetCounterSource replaced it
+var counterTotal = func(info SysInfo) int64 {
+	return int64(info.ReceivedTotal + info.SentTotal)
+}
+var throughputStarted bool
+var counterLock sync.Mutex
+
+// Counters are kept for a day more than a week, as the week starts on Monday
+const counterRetention = 8 * 24 * time.Hour
+
+// StartThroughput counts the messages of every sample, it can be called many times
+func StartThroughput() {
+	counterLock.Lock()
+	throughputStarted = true
+	counterLock.Unlock()
+	StartSampler()
+}
+
+// SetCounterSource replaces the counters in memory with the ones f returns for the last 8 days, so the counts reach
+// before the last restart. The next samples are counted with total, which must count like the counters of f.
+// The history store sets it, f is read only once
+func SetCounterSource(f func(from time.Time, to time.Time) []Counter, total func(info SysInfo) int64) {
+	now := time.Now()
+	seed := f(now.Add(-counterRetention), now)
+	counterLock.Lock()
+	defer counterLock.Unlock()
+	counters = seed
+	counterTotal = total
+}
+
+// recordCounter keeps the counters of a sample for a week
+func recordCounter(s Sample) {
+	// The total may take the lock of its store, so it is not called under counterLock
+	counterLock.Lock()
+	total := counterTotal
+	counterLock.Unlock()
+	c := Counter{At: s.At, Total: total(s.SysInfo)}
+	counterLock.Lock()
+	defer counterLock.Unlock()
+	if n := len(counters); n > 0 && counters[n-1].At.Truncate(time.Minute).Equal(c.At.Truncate(time.Minute)) {
+		counters[n-1] = c
+	} else {
+		counters = append(counters, c)
+	}
+	for len(counters) > 0 && s.At.Sub(counters[0].At) > counterRetention {
+		counters = counters[1:]
+	}
+}
+
+// ThroughputStats returns the message traffic at now, false if StartThroughput was not called
+func ThroughputStats(now time.Time) (Throughput, bool) {
+	counterLock.Lock()
+	if !throughputStarted {
+		counterLock.Unlock()
+		return Throughput{}, false
+	}
+	points := append([]Counter(nil), counters...)
+	counterLock.Unlock()
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monday := midnight.AddDate(0, 0, -(int(midnight.Weekday())+6)%7)
+	hourlyFrom := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location()).Add(-23 * time.Hour)
+	return throughputOf(points, now, midnight, monday, hourlyFrom), true
+}
+
+// throughputOf counts the messages between every two counters at the time of the later one
+func throughputOf(points []Counter, now time.Time, midnight time.Time, monday time.Time, hourlyFrom time.Time) Throughput {
+	t := Throughput{Hourly: make([]int64, 24), HourlyFrom: hourlyFrom}
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		// Counters restart from 0 without the history store
+		delta := max(b.Total-a.Total, 0)
+		if !b.At.Before(midnight) {
+			t.Today += delta
+		}
+		if !b.At.Before(monday) {
+			t.Week += delta
+		}
+		if h := int(b.At.Sub(t.HourlyFrom) / time.Hour); !b.At.Before(t.HourlyFrom) && h < 24 {
+			t.Hourly[h] += delta
+		}
+		if now.Sub(b.At) <= time.Hour {
+			rate := float64(delta) / max(b.At.Sub(a.At).Minutes(), 1)
+			t.PeakPerMinute = max(t.PeakPerMinute, rate)
+			if i == len(points)-1 {
+				t.PerMinute = rate
+			}
+		}
+	}
+	return t
+}
@@ -0,0 +1,106 @@
+package sysinfo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThroughputOf(t *testing.T) {
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 1, day, hour, minute, 0, 0, time.UTC) }
+	tests := []struct {
+		name string
+		// 2026-01-05 is a Monday
+		now    time.Time
+		points []Counter
+		want   Throughput
+		hourly map[int]int64
+	}{
+		{
+			name: "no counters",
+			now:  at(7, 10, 30),
+		},
+		{
+			name: "midweek",
+			now:  at(7, 10, 30),
+			points: []Counter{
+				{at(4, 23, 59), 0},
+				{at(5, 0, 1), 10},
+				{at(6, 11, 30), 30},
+				{at(7, 0, 10), 35},
+				{at(7, 10, 0), 95},
+				{at(7, 10, 20), 195},
+				// The counters restarted
+				{at(7, 10, 29), 50},
+				{at(7, 10, 30), 53},
+			},
+			want:   Throughput{PerMinute: 3, PeakPerMinute: 5, Today: 168, Week: 198},
+			hourly: map[int]int64{0: 20, 13: 5, 23: 163},
+		},
+		{
+			name: "24 hours reaching before Monday",
+			now:  at(5, 5, 30),
+			points: []Counter{
+				{at(4, 5, 0), 0},
+				{at(4, 12, 0), 10},
+				{at(5, 1, 0), 15},
+			},
+			want:   Throughput{Today: 5, Week: 5},
+			hourly: map[int]int64{6: 10, 19: 5},
+		},
+	}
+	for _, test := range tests {
+		now := test.now
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		monday := midnight.AddDate(0, 0, -(int(midnight.Weekday())+6)%7)
+		hourlyFrom := now.Truncate(time.Hour).Add(-23 * time.Hour)
+		got := throughputOf(test.points, now, midnight, monday, hourlyFrom)
+		if got.PerMinute != test.want.PerMinute || got.PeakPerMinute != test.want.PeakPerMinute ||
+			got.Today != test.want.Today || got.Week != test.want.Week {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+		if len(got.Hourly) != 24 || !got.HourlyFrom.Equal(hourlyFrom) {
+			t.Errorf("%s: got %d hours from %v, want 24 from %v", test.name, len(got.Hourly), got.HourlyFrom, hourlyFrom)
+			continue
+		}
+		for h, count := range got.Hourly {
+			if count != test.hourly[h] {
+				t.Errorf("%s: hour %d = %d, want %d", test.name, h, count, test.hourly[h])
+			}
+		}
+	}
+}
+
+func TestCounterSource(t *testing.T) {
+	counterLock.Lock()
+	previous, total, started := counters, counterTotal, throughputStarted
+	throughputStarted = true
+	counterLock.Unlock()
+	t.Cleanup(func() {
+		counterLock.Lock()
+		defer counterLock.Unlock()
+		counters, counterTotal, throughputStarted = previous, total, started
+	})
+
+	// A Wednesday, all the counters are in the same day and week
+	now := time.Date(2026, 1, 7, 12, 0, 0, 0, time.Local)
+	reads := 0
+	// The store counts across restarts, 1000 messages before this run
+	SetCounterSource(func(from time.Time, to time.Time) []Counter {
+		reads++
+		return []Counter{{now.Add(-2 * time.Minute), 1000}}
+	}, func(info SysInfo) int64 {
+		return 1000 + int64(info.ReceivedTotal+info.SentTotal)
+	})
+	recordCounter(Sample{SysInfo{ReceivedTotal: 3, SentTotal: 2}, now.Add(-time.Minute)})
+	recordCounter(Sample{SysInfo{ReceivedTotal: 8, SentTotal: 4}, now})
+
+	for i := 0; i < 3; i++ {
+		tp, ok := ThroughputStats(now)
+		if !ok || tp.Week != 12 || tp.PerMinute != 7 {
+			t.Errorf("got %+v, want 12 messages this week and 7 in the last minute", tp)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("the source was read %d times, want once", reads)
+	}
+}
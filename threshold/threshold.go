@@ -0,0 +1,89 @@
+package threshold
+
+import (
+	"sort"
+	"sync"
+)
+
+// Metric names
+const (
+	CPU    = "cpu"
+	Memory = "memory"
+	Disk   = "disk"
+)
+
+// Level is reached when a value is at least Min
+type Level struct {
+	// Name of the level, e.g. "ok", "warning" or "critical"
+	Name string `json:"name"`
+	// Min value (usually a percentage) of the level
+	Min float64 `json:"min"`
+	// Color used to draw the level, as a hex string
+	Color string `json:"color"`
+}
+
+// Thresholds is a list of levels, the highest one whose Min is reached wins
+type Thresholds []Level
+
+// Default thresholds for metrics without their own
+var Default = Thresholds{
+	{Name: "ok", Min: 0, Color: "#67C23AC0"},
+	{Name: "warning", Min: 40, Color: "#E6A23CC0"},
+	{Name: "critical", Min: 80, Color: "#F56C6CC0"},
+}
+
+var metrics = map[string]Thresholds{}
+var mounts = map[string]Thresholds{}
+var lock sync.RWMutex
+
+// Set sets the thresholds of a metric
+func Set(metric string, t Thresholds) {
+	lock.Lock()
+	defer lock.Unlock()
+	metrics[metric] = t
+}
+
+// SetMount sets the disk thresholds of a mountpoint, overriding the "disk" metric
+func SetMount(mountpoint string, t Thresholds) {
+	lock.Lock()
+	defer lock.Unlock()
+	mounts[mountpoint] = t
+}
+
+// For returns the thresholds of a metric
+func For(metric string) Thresholds {
+	lock.RLock()
+	defer lock.RUnlock()
+	if t, ok := metrics[metric]; ok {
+		return t
+	}
+	return Default
+}
+
+// ForMount returns the disk thresholds of a mountpoint
+func ForMount(mountpoint string) Thresholds {
+	lock.RLock()
+	t, ok := mounts[mountpoint]
+	lock.RUnlock()
+	if ok {
+		return t
+	}
+	return For(Disk)
+}
+
+// Level returns the level reached by v, or the lowest level if none is
+func (t Thresholds) Level(v float64) (result Level) {
+	if len(t) == 0 {
+		return
+	}
+	sorted := make(Thresholds, len(t))
+	copy(sorted, t)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Min < sorted[j].Min })
+	result = sorted[0]
+	for _, level := range sorted {
+		if v >= level.Min {
+			result = level
+		}
+	}
+	return
+}
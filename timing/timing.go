@@ -0,0 +1,107 @@
+package timing
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Phases of a status render, collectors are timed as Collect + "." + their name, e.g. "collect.cpu",
+// and deliveries as Deliver + "." + their mode, e.g. "deliver.base64"
+const (
+	// Collecting a snapshot
+	Collect = "collect"
+	// Collecting a snapshot in the background sampler, not part of a render. Collectors are timed as "sample.cpu"
+	Sample = "sample"
+	// Measuring and placing the panels
+	Layout = "layout"
+	// Drawing the panels
+	Draw = "draw"
+	// Encoding the image, see renderer.Encode
+	Encode = "encode"
+	// Passing the image to the adapter, see delivery.Deliver
+	Deliver = "deliver"
+	// The status command end to end
+	Total = "total"
+)
+
+// Window is how many of the latest durations of a phase its quantiles are computed over
+var Window = 1024
+
+// Stats are the durations of a phase
+type Stats struct {
+	// Durations recorded since the start, and their sum
+	Count int64         `json:"count"`
+	Sum   time.Duration `json:"sumNs"`
+	// Quantiles of the latest Window durations
+	P50 time.Duration `json:"p50Ns"`
+	P95 time.Duration `json:"p95Ns"`
+	P99 time.Duration `json:"p99Ns"`
+}
+
+type phase struct {
+	count  int64
+	sum    time.Duration
+	recent []time.Duration
+	// Next slot of recent to overwrite once it is full
+	next int
+}
+
+var phases = map[string]*phase{}
+var lock sync.Mutex
+
+// Record adds a duration to a phase
+func Record(name string, d time.Duration) {
+	lock.Lock()
+	defer lock.Unlock()
+	p := phases[name]
+	if p == nil {
+		p = &phase{}
+		phases[name] = p
+	}
+	p.count++
+	p.sum += d
+	if len(p.recent) < Window {
+		p.recent = append(p.recent, d)
+		return
+	}
+	p.recent[p.next] = d
+	p.next = (p.next + 1) % len(p.recent)
+}
+
+// Start starts timing a phase and returns the function recording it, e.g. defer timing.Start(timing.Draw)()
+func Start(name string) func() {
+	start := time.Now()
+	return func() {
+		Record(name, time.Since(start))
+	}
+}
+
+// Snapshot returns the stats of every phase recorded
+func Snapshot() map[string]Stats {
+	lock.Lock()
+	defer lock.Unlock()
+	result := map[string]Stats{}
+	for name, p := range phases {
+		sorted := append([]time.Duration(nil), p.recent...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		result[name] = Stats{
+			Count: p.count,
+			Sum:   p.sum,
+			P50:   quantile(sorted, 0.5),
+			P95:   quantile(sorted, 0.95),
+			P99:   quantile(sorted, 0.99),
+		}
+	}
+	return result
+}
+
+// quantile returns the nearest-rank quantile q of sorted durations
+func quantile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(float64(len(sorted))*q)) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
@@ -0,0 +1,48 @@
+package timing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuantile(t *testing.T) {
+	hundred := make([]time.Duration, 100)
+	for i := range hundred {
+		hundred[i] = time.Duration(i+1) * time.Millisecond
+	}
+	tests := []struct {
+		name   string
+		sorted []time.Duration
+		q      float64
+		want   time.Duration
+	}{
+		{"empty", nil, 0.5, 0},
+		{"single", []time.Duration{7}, 0.99, 7},
+		{"median of even", []time.Duration{1, 2, 3, 4}, 0.5, 2},
+		{"median of odd", []time.Duration{1, 2, 3}, 0.5, 2},
+		{"p95", hundred, 0.95, 95 * time.Millisecond},
+		{"p99", hundred, 0.99, 99 * time.Millisecond},
+		{"minimum", hundred, 0, time.Millisecond},
+		{"maximum", hundred, 1, 100 * time.Millisecond},
+	}
+	for _, test := range tests {
+		if got := quantile(test.sorted, test.q); got != test.want {
+			t.Errorf("%s: quantile(%v) = %v, want %v", test.name, test.q, got, test.want)
+		}
+	}
+}
+
+func TestSnapshotWindow(t *testing.T) {
+	phases = map[string]*phase{}
+	defer func(window int) { Window = window }(Window)
+	Window = 4
+	// The first two fall out of the window
+	for _, ms := range []int{100, 90, 1, 2, 4, 3} {
+		Record(Draw, time.Duration(ms)*time.Millisecond)
+	}
+	got := Snapshot()[Draw]
+	want := Stats{Count: 6, Sum: 200 * time.Millisecond, P50: 2 * time.Millisecond, P95: 4 * time.Millisecond, P99: 4 * time.Millisecond}
+	if got != want {
+		t.Errorf("Snapshot() = %+v, want %+v", got, want)
+	}
+}